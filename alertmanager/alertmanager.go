@@ -2,80 +2,288 @@ package alertmanager
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"sync"
+	"text/template"
 	"time"
+
+	"github.com/DucTran999/shared-pkg/retry"
 )
 
 var (
-	amInst *alertManager
-	once   sync.Once
+	amInst    *alertManager
+	amInstErr error
+	once      sync.Once
 )
 
+const DefaultClientTimeout time.Duration = 10 * time.Second
+
 type AlertManager interface {
 	Send(opts ...Options) error
 }
 
+// ContextAlertManager is an AlertManager whose sends can be canceled and
+// carry deadlines and request scoped values through the context.
+type ContextAlertManager interface {
+	AlertManager
+	SendContext(ctx context.Context, opts ...Options) error
+}
+
 type alertManager struct {
-	alertEndpoint string
-	httpClient    http.Client
+	peers      []string
+	quorum     int
+	httpClient *http.Client
+	headers    http.Header
+
+	// timeout overrides the timeout of httpClient once all options are set.
+	timeout time.Duration
+
+	// alerts holds the lifecycle handles keyed by label fingerprint.
+	alerts          map[string]*Alert
+	alertsMu        sync.Mutex
+	refreshInterval time.Duration
+
+	// Merged into every alert, values set on the alert itself win.
+	defaultLabels       Labels
+	defaultAnnotations  Annotations
+	annotationTemplates map[string]*template.Template
+
+	// suppressor drops duplicated and rate limited alerts before they are sent.
+	suppressor *suppressor
+
+	// retry retries transient delivery failures, spool keeps the batches that
+	// still failed. Both are optional.
+	retry retry.Retry
+	spool *spool
+
+	// auth sets the credentials of every request, tlsConfig is applied to
+	// the transport once all options are set.
+	auth      func(req *http.Request) error
+	tlsConfig *tls.Config
+
+	// sink replaces the Alertmanager peers as the delivery target when set.
+	sink Sink
+
+	// optionErr keeps the first error raised while applying client options.
+	optionErr error
 }
 
-func NewAlertManager(host string) (*alertManager, error) {
+// NewAlertManager creates a client that posts alerts to the Alertmanager at host.
+// Every call returns an independent instance, so clients for different
+// clusters can live side by side.
+//
+// Example:
+//
+//	am, err := NewAlertManager("http://localhost:9093",
+//	    WithTimeout(5*time.Second),
+//	    WithHeaders(map[string]string{"X-Scope-OrgID": "team-a"}),
+//	)
+func NewAlertManager(host string, opts ...ClientOption) (*alertManager, error) {
 	if host == "" {
 		return nil, ErrEmptyHost
 	}
 
-	once.Do(func() {
-		amInst = &alertManager{
-			alertEndpoint: fmt.Sprintf("%s/api/v2/alerts", host),
-			httpClient: http.Client{
-				Timeout: time.Second * 10, // Set a timeout for the HTTP client
-			},
+	return newAlertManager([]string{host}, opts...)
+}
+
+// NewAlertManagerCluster creates a client for an Alertmanager HA cluster.
+// Every alert is posted to all peers concurrently, like Prometheus does,
+// and Send succeeds once the quorum of peers (1 by default) accepts it.
+//
+// Example:
+//
+//	am, err := NewAlertManagerCluster([]string{
+//	    "http://am-0:9093",
+//	    "http://am-1:9093",
+//	    "http://am-2:9093",
+//	}, WithQuorum(2))
+func NewAlertManagerCluster(peers []string, opts ...ClientOption) (*alertManager, error) {
+	if len(peers) == 0 {
+		return nil, ErrEmptyHost
+	}
+
+	for _, peer := range peers {
+		if peer == "" {
+			return nil, ErrEmptyHost
 		}
+	}
+
+	return newAlertManager(peers, opts...)
+}
+
+// SharedAlertManager returns a process-wide client created on the first call.
+// The host and options of later calls are ignored, and so is a failed first
+// call: its error is returned by every later call.
+//
+// Deprecated: use NewAlertManager and pass the instance around instead.
+func SharedAlertManager(host string, opts ...ClientOption) (*alertManager, error) {
+	if host == "" {
+		return nil, ErrEmptyHost
+	}
+
+	once.Do(func() {
+		amInst, amInstErr = newAlertManager([]string{host}, opts...)
 	})
 
-	return amInst, nil
+	return amInst, amInstErr
 }
 
+// newAlertManager builds a client that posts alerts to the given peers.
+func newAlertManager(peers []string, opts ...ClientOption) (*alertManager, error) {
+	am := &alertManager{
+		peers:  append([]string(nil), peers...),
+		quorum: 1,
+		httpClient: &http.Client{
+			Timeout: DefaultClientTimeout, // Set a timeout for the HTTP client
+		},
+		headers:         make(http.Header),
+		alerts:          make(map[string]*Alert),
+		refreshInterval: DefaultRefreshInterval,
+		suppressor: &suppressor{
+			lastSent: make(map[string]time.Time),
+			buckets:  make(map[string]*tokenBucket),
+		},
+	}
+
+	for _, opt := range opts {
+		opt(am)
+	}
+
+	if am.optionErr != nil {
+		return nil, am.optionErr
+	}
+
+	am.applyTimeout()
+
+	if err := am.applyTLS(); err != nil {
+		return nil, err
+	}
+
+	if am.sink == nil && am.quorum > len(am.peers) {
+		return nil, fmt.Errorf("%w: %d of %d peers", ErrInvalidQuorum, am.quorum, len(am.peers))
+	}
+
+	return am, nil
+}
+
+// Send posts the alert without a deadline other than the client timeout.
+// It is a shorthand for SendContext with context.Background().
 func (am *alertManager) Send(opts ...Options) error {
+	return am.SendContext(context.Background(), opts...)
+}
+
+// SendContext posts the alert and gives up when ctx is done. The context is
+// also passed to the retries, so canceling it stops retrying.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+//	defer cancel()
+//
+//	err := am.SendContext(ctx, WithLabels(Labels{"alertname": "DiskFull"}))
+func (am *alertManager) SendContext(ctx context.Context, opts ...Options) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	alert, err := am.buildAlert(opts...)
+	if err != nil {
+		return err
+	}
+
+	// Suppressed alerts are counted in Stats, not reported as errors
+	adm, ok := am.suppressor.admit(alert)
+	if !ok {
+		return nil
+	}
+
+	arr := []options{alert}
+
+	if err := am.sendHttpRequest(ctx, arr); err != nil {
+		am.suppressor.forget(adm)
+		return err
+	}
+
+	return nil
+}
+
+// buildAlert applies the options, merges the client defaults and validates
+// the resulting alert payload.
+func (am *alertManager) buildAlert(opts ...Options) (options, error) {
 	var cfg options
 
 	for _, opt := range opts {
 		opt(&cfg)
 	}
 
+	if err := am.applyDefaults(&cfg); err != nil {
+		return cfg, err
+	}
+
 	// Validate required fields
-	if len(cfg.Labels) == 0 {
-		return fmt.Errorf("alert must have at least one label")
+	if err := cfg.Labels.Validate(); err != nil {
+		return cfg, err
 	}
 
-	// Ensure required labels like alertname are present
-	if _, ok := cfg.Labels["alertname"]; !ok {
-		return fmt.Errorf("alertname label is required")
+	if err := cfg.Annotations.Validate(); err != nil {
+		return cfg, err
 	}
 
 	if cfg.EndTime.IsZero() {
 		cfg.EndTime = time.Now().Add(time.Second * 30)
 	}
 
-	arr := []options{cfg}
-
-	return am.sendHttpRequest(arr)
+	return cfg, nil
 }
 
-func (am *alertManager) sendHttpRequest(optsPost []options) error {
+// sendHttpRequest posts the alerts to every peer and checks the quorum.
+// Batches that cannot be delivered are written to the spool when one is configured.
+func (am *alertManager) sendHttpRequest(ctx context.Context, optsPost []options) error {
 	jsonByte, err := json.Marshal(optsPost)
 	if err != nil {
 		return err
 	}
 
-	req, postErr := http.NewRequest(http.MethodPost, am.alertEndpoint, bytes.NewBuffer(jsonByte))
+	if err := am.deliver(ctx, jsonByte); err != nil {
+		am.spoolBatch(ctx, jsonByte, err)
+		return err
+	}
+
+	// The endpoint is reachable again, replay what was spooled while it was down
+	am.replaySpoolInBackground()
+
+	return nil
+}
+
+// deliver posts an encoded batch of alerts to the configured peers or sink.
+func (am *alertManager) deliver(ctx context.Context, body []byte) error {
+	if am.sink != nil {
+		return am.notifySink(ctx, body)
+	}
+
+	// Keep the plain error for a single Alertmanager
+	if len(am.peers) == 1 {
+		return am.postWithRetry(ctx, am.peers[0], body)
+	}
+
+	return am.fanOut(ctx, body)
+}
+
+// postAlerts posts an encoded batch of alerts to a single peer.
+func (am *alertManager) postAlerts(ctx context.Context, peer string, body []byte) error {
+	endpoint := fmt.Sprintf("%s/api/v2/alerts", peer)
+
+	req, postErr := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(body))
 	if postErr != nil {
 		return postErr
 	}
+	if err := am.setHeaders(req); err != nil {
+		return err
+	}
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send request
@@ -87,8 +295,23 @@ func (am *alertManager) sendHttpRequest(optsPost []options) error {
 
 	// Check for non-2xx response
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("alertmanager returned non-2xx status: %s", resp.Status)
+		return &APIError{StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
 	return nil
 }
+
+// setHeaders adds the client headers and credentials to an outgoing request.
+func (am *alertManager) setHeaders(req *http.Request) error {
+	for key, values := range am.headers {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+
+	if am.auth == nil {
+		return nil
+	}
+
+	return am.auth(req)
+}
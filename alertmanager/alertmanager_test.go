@@ -0,0 +1,158 @@
+package alertmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/DucTran999/shared-pkg/alertmanager/alertmanagertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewAlertManagerEmptyHost(t *testing.T) {
+	_, err := alertmanager.NewAlertManager("")
+	require.ErrorIs(t, err, alertmanager.ErrEmptyHost)
+}
+
+func Test_NewAlertManagerIndependentInstances(t *testing.T) {
+	var primaryHits, drHits atomic.Int32
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits.Add(1)
+	}))
+	defer primary.Close()
+
+	dr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		drHits.Add(1)
+	}))
+	defer dr.Close()
+
+	primaryAM, err := alertmanager.NewAlertManager(primary.URL)
+	require.NoError(t, err)
+
+	drAM, err := alertmanager.NewAlertManager(dr.URL)
+	require.NoError(t, err)
+
+	require.NoError(t, sendTestAlert(primaryAM))
+	require.NoError(t, sendTestAlert(drAM))
+	require.NoError(t, sendTestAlert(drAM))
+
+	assert.Equal(t, int32(1), primaryHits.Load())
+	assert.Equal(t, int32(2), drHits.Load())
+}
+
+func Test_NewAlertManagerWithOptions(t *testing.T) {
+	t.Run("sends custom headers", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "team-a", r.Header.Get("X-Scope-OrgID"))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		}))
+		defer srv.Close()
+
+		am, err := alertmanager.NewAlertManager(srv.URL,
+			alertmanager.WithHeaders(map[string]string{"X-Scope-OrgID": "team-a"}),
+		)
+		require.NoError(t, err)
+		require.NoError(t, sendTestAlert(am))
+	})
+
+	t.Run("request exceeds timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer srv.Close()
+
+		for _, timeoutFirst := range []bool{false, true} {
+			custom := &http.Client{}
+			opts := []alertmanager.ClientOption{
+				alertmanager.WithHTTPClient(custom),
+				alertmanager.WithTimeout(50 * time.Millisecond),
+			}
+			if timeoutFirst {
+				opts[0], opts[1] = opts[1], opts[0]
+			}
+
+			am, err := alertmanager.NewAlertManager(srv.URL, opts...)
+			require.NoError(t, err)
+
+			require.ErrorContains(t, sendTestAlert(am), "failed to send alert", "timeout first: %v", timeoutFirst)
+			assert.Zero(t, custom.Timeout, "custom client must not be modified")
+		}
+	})
+}
+
+func Test_SendOmitsUnsetTimes(t *testing.T) {
+	t.Run("client payload", func(t *testing.T) {
+		bodies := make(chan []byte, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			bodies <- body
+		}))
+		defer srv.Close()
+
+		am, err := alertmanager.NewAlertManager(srv.URL)
+		require.NoError(t, err)
+		require.NoError(t, sendTestAlert(am))
+
+		body := <-bodies
+		assert.NotContains(t, string(body), "startsAt")
+		assert.Contains(t, string(body), "endsAt")
+	})
+
+	t.Run("sink payload", func(t *testing.T) {
+		body, err := json.Marshal(alertmanager.PostableAlert{Labels: alertmanager.Labels{"alertname": "TestAlert"}})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"labels":{"alertname":"TestAlert"},"annotations":null}`, string(body))
+	})
+}
+
+func Test_SendContext(t *testing.T) {
+	t.Run("canceled context sends nothing", func(t *testing.T) {
+		fake := alertmanagertest.NewServer(t)
+
+		am, err := alertmanager.NewAlertManager(fake.URL)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err = am.SendContext(ctx, alertmanager.WithLabels(alertmanager.Labels{"alertname": "TestAlert"}))
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, fake.Requests())
+	})
+
+	t.Run("deadline stops a slow request", func(t *testing.T) {
+		fake := alertmanagertest.NewServer(t)
+		fake.SetLatency(500 * time.Millisecond)
+
+		am, err := alertmanager.NewAlertManager(fake.URL)
+		require.NoError(t, err)
+
+		var sender alertmanager.ContextAlertManager = am
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err = sender.SendContext(ctx, alertmanager.WithLabels(alertmanager.Labels{"alertname": "TestAlert"}))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Empty(t, fake.Received())
+	})
+}
+
+func Test_SharedAlertManager(t *testing.T) {
+	first, err := alertmanager.SharedAlertManager("http://first:9093")
+	require.NoError(t, err)
+
+	second, err := alertmanager.SharedAlertManager("http://second:9093")
+	require.NoError(t, err)
+
+	assert.Same(t, first, second)
+}
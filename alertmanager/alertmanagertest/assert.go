@@ -0,0 +1,128 @@
+package alertmanagertest
+
+import (
+	"time"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+)
+
+// TB is the subset of testing.TB the assertion helpers need.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// AssertReceived checks that at least one posted alert matches all matchers.
+func (s *Server) AssertReceived(tb TB, matchers ...alertmanager.Matcher) bool {
+	tb.Helper()
+
+	if s.countReceived(matchers) == 0 {
+		tb.Errorf("no alert matching %v was received, got %d alerts", matchers, len(s.Received()))
+		return false
+	}
+
+	return true
+}
+
+// AssertNotReceived checks that no posted alert matches all matchers.
+func (s *Server) AssertNotReceived(tb TB, matchers ...alertmanager.Matcher) bool {
+	tb.Helper()
+
+	if n := s.countReceived(matchers); n > 0 {
+		tb.Errorf("expected no alert matching %v, got %d", matchers, n)
+		return false
+	}
+
+	return true
+}
+
+// AssertFiring checks that the alert with exactly these labels is currently firing.
+func (s *Server) AssertFiring(tb TB, labels alertmanager.Labels) bool {
+	tb.Helper()
+
+	alert, ok := s.alert(labels)
+	if !ok {
+		tb.Errorf("alert %v was never received", labels)
+		return false
+	}
+
+	if !alert.EndsAt.IsZero() && !alert.EndsAt.After(time.Now()) {
+		tb.Errorf("alert %v is resolved since %s, expected it to be firing", labels, alert.EndsAt)
+		return false
+	}
+
+	return true
+}
+
+// AssertResolved checks that the alert with exactly these labels was received and is resolved.
+func (s *Server) AssertResolved(tb TB, labels alertmanager.Labels) bool {
+	tb.Helper()
+
+	alert, ok := s.alert(labels)
+	if !ok {
+		tb.Errorf("alert %v was never received", labels)
+		return false
+	}
+
+	if alert.EndsAt.IsZero() || alert.EndsAt.After(time.Now()) {
+		tb.Errorf("alert %v is still firing, expected it to be resolved", labels)
+		return false
+	}
+
+	return true
+}
+
+// WaitForAlerts waits until at least n alerts were posted or the timeout expires,
+// which is handy with the async sender. It returns the posted alerts.
+func (s *Server) WaitForAlerts(tb TB, n int, timeout time.Duration) []PostedAlert {
+	tb.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		received := s.Received()
+		if len(received) >= n {
+			return received
+		}
+
+		if time.Now().After(deadline) {
+			tb.Errorf("expected at least %d alerts within %s, got %d", n, timeout, len(received))
+			return received
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (s *Server) countReceived(matchers []alertmanager.Matcher) int {
+	n := 0
+	for _, a := range s.Received() {
+		if matchesAll(matchers, a.Labels) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// alert returns the last posted state of the alert with exactly these labels.
+func (s *Server) alert(labels alertmanager.Labels) (alertmanager.GettableAlert, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	alert, ok := s.alerts[labels.Fingerprint()]
+	if !ok {
+		return alertmanager.GettableAlert{}, false
+	}
+
+	return *alert, true
+}
+
+func matchesAll(matchers []alertmanager.Matcher, labels alertmanager.Labels) bool {
+	for _, m := range matchers {
+		if !m.Matches(labels) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,477 @@
+// Package alertmanagertest provides an in-process fake Alertmanager for tests.
+//
+// The fake implements the v2 alerts and silences endpoints used by the
+// alertmanager package, records every alert it receives and can inject
+// failures and latency.
+//
+// Example:
+//
+//	func Test_Checkout(t *testing.T) {
+//	    fake := alertmanagertest.NewServer(t)
+//	    am, _ := alertmanager.NewAlertManager(fake.URL)
+//
+//	    // ... code under test sends alerts with am ...
+//
+//	    fake.AssertFiring(t, alertmanager.Labels{"alertname": "PaymentFailed"})
+//	}
+package alertmanagertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+)
+
+// PostedAlert is an alert as it was posted to the fake.
+type PostedAlert struct {
+	Labels       alertmanager.Labels      `json:"labels"`
+	Annotations  alertmanager.Annotations `json:"annotations"`
+	StartsAt     time.Time                `json:"startsAt"`
+	EndsAt       time.Time                `json:"endsAt"`
+	GeneratorURL string                   `json:"generatorURL"`
+}
+
+// Request is a request received by the fake.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+}
+
+// Server is a fake Alertmanager listening on a local port.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	received []PostedAlert
+	alerts   map[string]*alertmanager.GettableAlert
+	silences map[string]*alertmanager.Silence
+	nextID   int
+
+	failStatus int
+	failCount  int // remaining failures, -1 fails until ClearFailures
+	latency    time.Duration
+}
+
+// NewServer starts a fake Alertmanager that is closed when the test ends.
+func NewServer(tb testing.TB) *Server {
+	tb.Helper()
+
+	s := &Server{
+		alerts:   make(map[string]*alertmanager.GettableAlert),
+		silences: make(map[string]*alertmanager.Silence),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v2/alerts", s.postAlerts)
+	mux.HandleFunc("GET /api/v2/alerts", s.getAlerts)
+	mux.HandleFunc("GET /api/v2/alerts/groups", s.getAlertGroups)
+	mux.HandleFunc("POST /api/v2/silences", s.postSilence)
+	mux.HandleFunc("GET /api/v2/silences", s.getSilences)
+	mux.HandleFunc("GET /api/v2/silence/{id}", s.getSilence)
+	mux.HandleFunc("DELETE /api/v2/silence/{id}", s.deleteSilence)
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+	tb.Cleanup(s.Close)
+
+	return s
+}
+
+// FailNext makes the next n requests fail with the given status code.
+func (s *Server) FailNext(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failCount = n
+	s.failStatus = status
+}
+
+// FailAlways makes every request fail with the given status code until ClearFailures is called.
+func (s *Server) FailAlways(status int) {
+	s.FailNext(-1, status)
+}
+
+// ClearFailures stops injecting failures.
+func (s *Server) ClearFailures() {
+	s.FailNext(0, 0)
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = d
+}
+
+// Requests returns every request received so far, failed ones included.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// Received returns every alert accepted so far, in the order it was posted.
+func (s *Server) Received() []PostedAlert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]PostedAlert(nil), s.received...)
+}
+
+// Alerts returns the current state of every alert, one per label set.
+func (s *Server) Alerts() []alertmanager.GettableAlert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.alertsLocked(func(alertmanager.GettableAlert) bool { return true })
+}
+
+// Reset forgets every request, alert and silence and clears the injected failures.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = nil
+	s.received = nil
+	s.alerts = make(map[string]*alertmanager.GettableAlert)
+	s.silences = make(map[string]*alertmanager.Silence)
+	s.failCount, s.failStatus, s.latency = 0, 0, 0
+}
+
+// middleware records the request and applies the injected latency and failures.
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone()})
+
+		latency := s.latency
+		failStatus := 0
+		if s.failCount != 0 {
+			failStatus = s.failStatus
+			if s.failCount > 0 {
+				s.failCount--
+			}
+		}
+		s.mu.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if failStatus != 0 {
+			http.Error(w, "injected failure", failStatus)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) postAlerts(w http.ResponseWriter, r *http.Request) {
+	var alerts []PostedAlert
+	if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, a := range alerts {
+		if len(a.Labels) == 0 {
+			http.Error(w, "at least one label pair required", http.StatusBadRequest)
+			return
+		}
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range alerts {
+		s.received = append(s.received, a)
+
+		fingerprint := a.Labels.Fingerprint()
+		stored, ok := s.alerts[fingerprint]
+		if !ok {
+			stored = &alertmanager.GettableAlert{
+				Fingerprint: fingerprint,
+				StartsAt:    a.StartsAt,
+				Receivers:   []alertmanager.Receiver{{Name: "fake"}},
+			}
+			s.alerts[fingerprint] = stored
+		}
+
+		if stored.StartsAt.IsZero() || (!a.StartsAt.IsZero() && a.StartsAt.Before(stored.StartsAt)) {
+			stored.StartsAt = a.StartsAt
+		}
+		if stored.StartsAt.IsZero() {
+			stored.StartsAt = now
+		}
+
+		stored.Labels = a.Labels
+		stored.Annotations = a.Annotations
+		stored.EndsAt = a.EndsAt
+		stored.GeneratorURL = a.GeneratorURL
+		stored.UpdatedAt = now
+	}
+}
+
+func (s *Server) getAlerts(w http.ResponseWriter, r *http.Request) {
+	keep, err := alertFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	alerts := s.alertsLocked(keep)
+	s.mu.Unlock()
+
+	writeJSON(w, alerts)
+}
+
+func (s *Server) getAlertGroups(w http.ResponseWriter, r *http.Request) {
+	keep, err := alertFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	alerts := s.alertsLocked(keep)
+	s.mu.Unlock()
+
+	// The fake routes everything to a single receiver without grouping labels
+	groups := []alertmanager.AlertGroup{}
+	if len(alerts) > 0 {
+		groups = append(groups, alertmanager.AlertGroup{
+			Labels:   alertmanager.Labels{},
+			Receiver: alertmanager.Receiver{Name: "fake"},
+			Alerts:   alerts,
+		})
+	}
+
+	writeJSON(w, groups)
+}
+
+// alertsLocked returns the unresolved alerts with their silence status. The caller must hold s.mu.
+func (s *Server) alertsLocked(keep func(alertmanager.GettableAlert) bool) []alertmanager.GettableAlert {
+	now := time.Now()
+
+	alerts := []alertmanager.GettableAlert{}
+	for _, stored := range s.alerts {
+		if !stored.EndsAt.IsZero() && !stored.EndsAt.After(now) {
+			continue
+		}
+
+		alert := *stored
+		alert.Status = alertmanager.AlertStatus{
+			State:       alertmanager.AlertStateActive,
+			SilencedBy:  []string{},
+			InhibitedBy: []string{},
+		}
+
+		for _, silence := range s.silences {
+			if silenceState(silence, now) == alertmanager.SilenceActive && silenceMatches(silence, alert.Labels) {
+				alert.Status.State = alertmanager.AlertStateSuppressed
+				alert.Status.SilencedBy = append(alert.Status.SilencedBy, silence.ID)
+			}
+		}
+
+		if keep(alert) {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].Fingerprint < alerts[j].Fingerprint })
+
+	return alerts
+}
+
+// alertFilter builds a filter from the query parameters of GET /api/v2/alerts.
+func alertFilter(r *http.Request) (func(alertmanager.GettableAlert) bool, error) {
+	query := r.URL.Query()
+
+	matchers, err := parseMatchers(query["filter"])
+	if err != nil {
+		return nil, err
+	}
+
+	flag := func(name string) bool {
+		v, err := strconv.ParseBool(query.Get(name))
+		return err != nil || v
+	}
+	active, silenced := flag("active"), flag("silenced")
+
+	return func(a alertmanager.GettableAlert) bool {
+		if !matchesAll(matchers, a.Labels) {
+			return false
+		}
+
+		if len(a.Status.SilencedBy) > 0 {
+			return silenced
+		}
+
+		return active
+	}, nil
+}
+
+func (s *Server) postSilence(w http.ResponseWriter, r *http.Request) {
+	var silence alertmanager.Silence
+	if err := json.NewDecoder(r.Body).Decode(&silence); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := silence.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if silence.ID == "" {
+		s.nextID++
+		silence.ID = fmt.Sprintf("silence-%d", s.nextID)
+	} else if _, ok := s.silences[silence.ID]; !ok {
+		http.Error(w, "silence not found", http.StatusNotFound)
+		return
+	}
+
+	silence.UpdatedAt = time.Now()
+	s.silences[silence.ID] = &silence
+
+	writeJSON(w, map[string]string{"silenceID": silence.ID})
+}
+
+func (s *Server) getSilences(w http.ResponseWriter, r *http.Request) {
+	filters, err := parseMatchers(r.URL.Query()["filter"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	silences := []alertmanager.Silence{}
+	for _, silence := range s.silences {
+		if !hasMatchers(silence, filters) {
+			continue
+		}
+
+		out := *silence
+		out.Status.State = silenceState(silence, now)
+		silences = append(silences, out)
+	}
+
+	sort.Slice(silences, func(i, j int) bool { return silences[i].ID < silences[j].ID })
+
+	writeJSON(w, silences)
+}
+
+func (s *Server) getSilence(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	silence, ok := s.silences[r.PathValue("id")]
+	if !ok {
+		http.Error(w, "silence not found", http.StatusNotFound)
+		return
+	}
+
+	out := *silence
+	out.Status.State = silenceState(silence, time.Now())
+
+	writeJSON(w, out)
+}
+
+func (s *Server) deleteSilence(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	silence, ok := s.silences[r.PathValue("id")]
+	if !ok {
+		http.Error(w, "silence not found", http.StatusNotFound)
+		return
+	}
+
+	now := time.Now()
+	if silence.StartsAt.After(now) {
+		silence.StartsAt = now
+	}
+	silence.EndsAt = now
+	silence.UpdatedAt = now
+}
+
+func silenceState(s *alertmanager.Silence, now time.Time) alertmanager.SilenceState {
+	switch {
+	case !s.EndsAt.After(now):
+		return alertmanager.SilenceExpired
+	case s.StartsAt.After(now):
+		return alertmanager.SilencePending
+	default:
+		return alertmanager.SilenceActive
+	}
+}
+
+func silenceMatches(s *alertmanager.Silence, labels alertmanager.Labels) bool {
+	return matchesAll(s.Matchers, labels)
+}
+
+// hasMatchers reports whether the silence contains every filter matcher, which is
+// how Alertmanager filters silences.
+func hasMatchers(s *alertmanager.Silence, filters []alertmanager.Matcher) bool {
+	for _, f := range filters {
+		found := false
+		for _, m := range s.Matchers {
+			if m == f {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseMatchers(filters []string) ([]alertmanager.Matcher, error) {
+	matchers := make([]alertmanager.Matcher, 0, len(filters))
+	for _, f := range filters {
+		m, err := alertmanager.ParseMatcher(f)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+
+	return matchers, nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
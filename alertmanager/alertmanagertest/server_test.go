@@ -0,0 +1,117 @@
+package alertmanagertest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/DucTran999/shared-pkg/alertmanager/alertmanagertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTB captures assertion failures instead of failing the test.
+type recordingTB struct {
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func Test_FakeRecordsAlerts(t *testing.T) {
+	fake := alertmanagertest.NewServer(t)
+	ctx := context.Background()
+
+	am, err := alertmanager.NewAlertManager(fake.URL)
+	require.NoError(t, err)
+
+	firing := alertmanager.Labels{"alertname": "HighLatency", "service": "checkout"}
+	resolved := alertmanager.Labels{"alertname": "DiskFull", "service": "db"}
+
+	require.NoError(t, am.Send(alertmanager.WithLabels(firing)))
+	require.NoError(t, am.Send(alertmanager.WithLabels(resolved)))
+	require.NoError(t, am.Send(alertmanager.WithLabels(resolved), alertmanager.WithDuration(0)))
+
+	assert.Len(t, fake.Received(), 3)
+	fake.AssertReceived(t, alertmanager.Matcher{Name: "service", Value: "checkout"})
+	fake.AssertNotReceived(t, alertmanager.Matcher{Name: "service", Value: "search"})
+	fake.AssertFiring(t, firing)
+	fake.AssertResolved(t, resolved)
+
+	alerts, err := am.GetAlerts(ctx, alertmanager.AlertFilter{})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1, "resolved alerts are not returned")
+	assert.Equal(t, firing, alerts[0].Labels)
+
+	rec := &recordingTB{}
+	fake.AssertFiring(rec, resolved)
+	fake.AssertReceived(rec, alertmanager.Matcher{Name: "service", Value: "search"})
+	assert.Len(t, rec.errors, 2)
+}
+
+func Test_FakeSilences(t *testing.T) {
+	fake := alertmanagertest.NewServer(t)
+	ctx := context.Background()
+
+	am, err := alertmanager.NewAlertManager(fake.URL)
+	require.NoError(t, err)
+
+	labels := alertmanager.Labels{"alertname": "HighLatency", "service": "checkout"}
+	require.NoError(t, am.Send(alertmanager.WithLabels(labels)))
+
+	id, err := am.CreateSilence(ctx, alertmanager.Silence{
+		Matchers:  []alertmanager.Matcher{{Name: "service", Value: "check.*", Type: alertmanager.MatchRegexp}},
+		EndsAt:    time.Now().Add(time.Hour),
+		CreatedBy: "test",
+		Comment:   "maintenance",
+	})
+	require.NoError(t, err)
+
+	alerts, err := am.GetAlerts(ctx, alertmanager.AlertFilter{})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, alertmanager.AlertStateSuppressed, alerts[0].Status.State)
+	assert.Equal(t, []string{id}, alerts[0].Status.SilencedBy)
+
+	alerts, err = am.GetAlerts(ctx, alertmanager.AlertFilter{ExcludeSilenced: true})
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	require.NoError(t, am.ExpireSilence(ctx, id))
+
+	silence, err := am.GetSilence(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, alertmanager.SilenceExpired, silence.Status.State)
+}
+
+func Test_FakeInjectsFailures(t *testing.T) {
+	fake := alertmanagertest.NewServer(t)
+
+	am, err := alertmanager.NewAlertManager(fake.URL, alertmanager.WithTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+
+	send := func() error {
+		return am.Send(alertmanager.WithLabels(alertmanager.Labels{"alertname": "Test"}))
+	}
+
+	fake.FailNext(1, http.StatusServiceUnavailable)
+	err = send()
+
+	var apiErr *alertmanager.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	require.NoError(t, send())
+
+	fake.SetLatency(100 * time.Millisecond)
+	require.Error(t, send())
+
+	fake.Reset()
+	require.NoError(t, send())
+	assert.Len(t, fake.Requests(), 1)
+}
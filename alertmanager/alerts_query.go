@@ -0,0 +1,132 @@
+package alertmanager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AlertState is the state Alertmanager reports for a received alert.
+type AlertState string
+
+const (
+	AlertStateActive      AlertState = "active"
+	AlertStateSuppressed  AlertState = "suppressed"
+	AlertStateUnprocessed AlertState = "unprocessed"
+)
+
+// AlertFilter narrows down the alerts returned by GetAlerts and GetAlertGroups.
+// The zero value returns every alert, like the Alertmanager API does.
+type AlertFilter struct {
+	// Matchers keeps only the alerts whose labels satisfy all matchers.
+	Matchers []Matcher
+
+	// Receiver keeps only the alerts routed to receivers matching this regular expression.
+	Receiver string
+
+	// The Exclude flags drop alerts in the given state. The groups endpoint
+	// does not support ExcludeUnprocessed, GetAlertGroups rejects it.
+	ExcludeActive      bool
+	ExcludeSilenced    bool
+	ExcludeInhibited   bool
+	ExcludeUnprocessed bool
+}
+
+func (f AlertFilter) query() (url.Values, error) {
+	query := url.Values{}
+	for _, m := range f.Matchers {
+		if err := m.Validate(); err != nil {
+			return nil, err
+		}
+		query.Add("filter", m.String())
+	}
+
+	if f.Receiver != "" {
+		query.Set("receiver", f.Receiver)
+	}
+
+	query.Set("active", strconv.FormatBool(!f.ExcludeActive))
+	query.Set("silenced", strconv.FormatBool(!f.ExcludeSilenced))
+	query.Set("inhibited", strconv.FormatBool(!f.ExcludeInhibited))
+
+	return query, nil
+}
+
+// Receiver is an Alertmanager receiver an alert is routed to.
+type Receiver struct {
+	Name string `json:"name"`
+}
+
+// AlertStatus tells whether an alert is active or suppressed, and by what.
+type AlertStatus struct {
+	State       AlertState `json:"state"`
+	SilencedBy  []string   `json:"silencedBy"`
+	InhibitedBy []string   `json:"inhibitedBy"`
+}
+
+// GettableAlert is an alert as currently known by Alertmanager.
+type GettableAlert struct {
+	Fingerprint  string      `json:"fingerprint"`
+	Labels       Labels      `json:"labels"`
+	Annotations  Annotations `json:"annotations"`
+	StartsAt     time.Time   `json:"startsAt"`
+	EndsAt       time.Time   `json:"endsAt"`
+	UpdatedAt    time.Time   `json:"updatedAt"`
+	GeneratorURL string      `json:"generatorURL"`
+	Receivers    []Receiver  `json:"receivers"`
+	Status       AlertStatus `json:"status"`
+}
+
+// AlertGroup is a set of alerts grouped by the route of a receiver.
+type AlertGroup struct {
+	Labels   Labels          `json:"labels"`
+	Receiver Receiver        `json:"receiver"`
+	Alerts   []GettableAlert `json:"alerts"`
+}
+
+// GetAlerts returns the alerts currently held by Alertmanager.
+//
+// Example:
+//
+//	alerts, err := am.GetAlerts(ctx, AlertFilter{
+//	    Matchers:        []Matcher{{Name: "service", Value: "checkout"}},
+//	    ExcludeSilenced: true,
+//	})
+func (am *alertManager) GetAlerts(ctx context.Context, filter AlertFilter) ([]GettableAlert, error) {
+	query, err := filter.query()
+	if err != nil {
+		return nil, err
+	}
+	query.Set("unprocessed", strconv.FormatBool(!filter.ExcludeUnprocessed))
+
+	var alerts []GettableAlert
+	if err := am.doAPI(ctx, http.MethodGet, "/alerts", query, nil, &alerts); err != nil {
+		return nil, fmt.Errorf("failed to get alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// GetAlertGroups returns the alerts currently held by Alertmanager grouped as
+// they are notified to receivers. It fails with ErrInvalidFilter when
+// filter.ExcludeUnprocessed is set, which the groups endpoint does not support.
+func (am *alertManager) GetAlertGroups(ctx context.Context, filter AlertFilter) ([]AlertGroup, error) {
+	if filter.ExcludeUnprocessed {
+		return nil, fmt.Errorf("%w: alert groups cannot exclude unprocessed alerts", ErrInvalidFilter)
+	}
+
+	query, err := filter.query()
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []AlertGroup
+	if err := am.doAPI(ctx, http.MethodGet, "/alerts/groups", query, nil, &groups); err != nil {
+		return nil, fmt.Errorf("failed to get alert groups: %w", err)
+	}
+
+	return groups, nil
+}
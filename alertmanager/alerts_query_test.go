@@ -0,0 +1,90 @@
+package alertmanager_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const alertsResponse = `[{
+	"fingerprint": "6f2a3c1d",
+	"labels": {"alertname": "HighLatency", "service": "checkout"},
+	"annotations": {"summary": "p99 above 1s"},
+	"startsAt": "2025-01-01T10:00:00Z",
+	"endsAt": "2025-01-01T10:05:00Z",
+	"updatedAt": "2025-01-01T10:01:00Z",
+	"generatorURL": "http://grafana/d/latency",
+	"receivers": [{"name": "oncall"}],
+	"status": {"state": "suppressed", "silencedBy": ["silence-1"], "inhibitedBy": []}
+}]`
+
+func Test_GetAlerts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/alerts", r.URL.Path)
+
+		query := r.URL.Query()
+		assert.Equal(t, []string{`service="checkout"`}, query["filter"])
+		assert.Equal(t, "oncall", query.Get("receiver"))
+		assert.Equal(t, "true", query.Get("active"))
+		assert.Equal(t, "false", query.Get("inhibited"))
+		assert.Equal(t, "true", query.Get("unprocessed"))
+
+		w.Write([]byte(alertsResponse))
+	}))
+	defer srv.Close()
+
+	am, err := alertmanager.NewAlertManager(srv.URL)
+	require.NoError(t, err)
+
+	alerts, err := am.GetAlerts(context.Background(), alertmanager.AlertFilter{
+		Matchers:         []alertmanager.Matcher{{Name: "service", Value: "checkout"}},
+		Receiver:         "oncall",
+		ExcludeInhibited: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+
+	alert := alerts[0]
+	assert.Equal(t, "HighLatency", alert.Labels["alertname"])
+	assert.Equal(t, alertmanager.AlertStateSuppressed, alert.Status.State)
+	assert.Equal(t, []string{"silence-1"}, alert.Status.SilencedBy)
+	assert.Equal(t, []alertmanager.Receiver{{Name: "oncall"}}, alert.Receivers)
+}
+
+func Test_GetAlertGroups(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/alerts/groups", r.URL.Path)
+		assert.False(t, r.URL.Query().Has("unprocessed"), "the groups endpoint does not support it")
+
+		w.Write([]byte(`[{"labels": {"service": "checkout"}, "receiver": {"name": "oncall"}, "alerts": ` + alertsResponse + `}]`))
+	}))
+	defer srv.Close()
+
+	am, err := alertmanager.NewAlertManager(srv.URL)
+	require.NoError(t, err)
+
+	groups, err := am.GetAlertGroups(context.Background(), alertmanager.AlertFilter{})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "oncall", groups[0].Receiver.Name)
+	require.Len(t, groups[0].Alerts, 1)
+	assert.Equal(t, "6f2a3c1d", groups[0].Alerts[0].Fingerprint)
+
+	_, err = am.GetAlertGroups(context.Background(), alertmanager.AlertFilter{ExcludeUnprocessed: true})
+	require.ErrorIs(t, err, alertmanager.ErrInvalidFilter)
+}
+
+func Test_GetAlertsInvalidFilter(t *testing.T) {
+	am, err := alertmanager.NewAlertManager("http://localhost:9093")
+	require.NoError(t, err)
+
+	_, err = am.GetAlerts(context.Background(), alertmanager.AlertFilter{
+		Matchers: []alertmanager.Matcher{{Name: "bad-name", Value: "x"}},
+	})
+	require.ErrorIs(t, err, alertmanager.ErrInvalidMatcher)
+}
@@ -0,0 +1,117 @@
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// APIError is returned when Alertmanager answers an API call with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("alertmanager returned non-2xx status: %s", e.Status)
+	}
+
+	return fmt.Sprintf("alertmanager returned non-2xx status: %s: %s", e.Status, e.Message)
+}
+
+// doAPI calls an Alertmanager v2 API endpoint and decodes the JSON response into out.
+// State such as silences is gossiped between peers, so the peers are tried
+// in order and the first answer wins.
+func (am *alertManager) doAPI(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	if len(am.peers) == 0 {
+		return ErrNoPeers
+	}
+
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, peer := range am.peers {
+		err := am.doPeerAPI(ctx, peer, method, path, query, body, out)
+		if err == nil {
+			return nil
+		}
+
+		// Every peer shares the same state, so a client error will not change on another one
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError {
+			return err
+		}
+
+		if len(am.peers) == 1 {
+			return err
+		}
+		errs = append(errs, PeerError{Peer: peer, Err: err})
+	}
+
+	return errors.Join(errs...)
+}
+
+func (am *alertManager) doPeerAPI(
+	ctx context.Context, peer, method, path string, query url.Values, body []byte, out any,
+) error {
+	endpoint := fmt.Sprintf("%s/api/v2%s", peer, path)
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	if err := am.setHeaders(req); err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := am.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call alertmanager: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Message:    strings.TrimSpace(string(msg)),
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode alertmanager response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,353 @@
+package alertmanager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultQueueSize     int           = 1024
+	DefaultBatchSize     int           = 64
+	DefaultFlushInterval time.Duration = 5 * time.Second
+)
+
+// OverflowPolicy decides what happens to an alert when the async queue is full.
+type OverflowPolicy int
+
+const (
+	// DropNewest rejects the incoming alert and keeps the queued ones.
+	DropNewest OverflowPolicy = iota
+
+	// DropOldest evicts the oldest queued alert to make room for the incoming one.
+	DropOldest
+
+	// Block waits until the queue has room. Use it only when losing alerts
+	// is worse than stalling the caller.
+	Block
+)
+
+// AsyncConfig holds the configuration settings for the async alert sender.
+type AsyncConfig struct {
+	// QueueSize is the maximum number of alerts buffered in memory.
+	QueueSize int
+
+	// BatchSize is the maximum number of alerts posted in a single request.
+	// A batch is flushed as soon as it reaches this size.
+	BatchSize int
+
+	// FlushInterval is the maximum time an alert waits in the queue
+	// before a partial batch is flushed.
+	FlushInterval time.Duration
+
+	// OverflowPolicy decides what to do with alerts when the queue is full.
+	OverflowPolicy OverflowPolicy
+}
+
+// AsyncStats is a snapshot of the async sender counters.
+type AsyncStats struct {
+	Enqueued uint64 // Alerts accepted into the queue
+	Sent     uint64 // Alerts delivered to Alertmanager
+	Dropped  uint64 // Alerts discarded by the overflow policy
+	Failed   uint64 // Alerts whose batch could not be delivered
+
+	// Stats holds the alerts suppressed before reaching the queue.
+	Stats
+}
+
+// flushRequest asks the worker to drain the queue and report the result.
+type flushRequest struct {
+	ctx   context.Context
+	reply chan error
+}
+
+type asyncAlertManager struct {
+	am     *alertManager
+	config AsyncConfig
+
+	queue   chan options
+	flushCh chan flushRequest
+	done    chan struct{}
+	stopped chan struct{}
+
+	// mu guards closed so no alert is enqueued after the final flush.
+	mu        sync.RWMutex
+	closed    bool
+	closeOnce sync.Once
+
+	enqueued atomic.Uint64
+	sent     atomic.Uint64
+	dropped  atomic.Uint64
+	failed   atomic.Uint64
+}
+
+// NewAsyncAlertManager creates an alert sender that never waits on Alertmanager.
+// Alerts are buffered in a bounded queue and posted in batches, either when
+// BatchSize alerts are pending or every FlushInterval.
+//
+// Zero values in config fall back to:
+//   - QueueSize: 1024
+//   - BatchSize: 64
+//   - FlushInterval: 5s
+//   - OverflowPolicy: DropNewest
+//
+// The client options are applied to the underlying Alertmanager client.
+// Call Close on shutdown to deliver the alerts still in the queue.
+func NewAsyncAlertManager(host string, config AsyncConfig, opts ...ClientOption) (*asyncAlertManager, error) {
+	if host == "" {
+		return nil, ErrEmptyHost
+	}
+
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+
+	am, err := newAlertManager([]string{host}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &asyncAlertManager{
+		am:      am,
+		config:  config,
+		queue:   make(chan options, config.QueueSize),
+		flushCh: make(chan flushRequest),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go a.run()
+
+	return a, nil
+}
+
+// Send validates the alert and puts it on the queue. It returns ErrQueueFull
+// when the alert is rejected by the DropNewest policy.
+func (a *asyncAlertManager) Send(opts ...Options) error {
+	return a.SendContext(context.Background(), opts...)
+}
+
+// SendContext is like Send but stops waiting for room in the queue when ctx
+// is done, which only matters with the Block policy. The alert is delivered
+// later by the worker, so ctx does not bound the delivery itself.
+func (a *asyncAlertManager) SendContext(ctx context.Context, opts ...Options) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	alert, err := a.am.buildAlert(opts...)
+	if err != nil {
+		return err
+	}
+
+	adm, ok := a.am.suppressor.admit(alert)
+	if !ok {
+		return nil
+	}
+	alert.admission = adm
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		a.am.suppressor.forget(adm)
+		return ErrSenderClosed
+	}
+
+	if err := a.enqueue(ctx, alert); err != nil {
+		a.am.suppressor.forget(adm)
+		return err
+	}
+
+	return nil
+}
+
+func (a *asyncAlertManager) enqueue(ctx context.Context, alert options) error {
+	switch a.config.OverflowPolicy {
+	case Block:
+		select {
+		case a.queue <- alert:
+		case <-ctx.Done():
+			a.dropped.Add(1)
+			return ctx.Err()
+		}
+
+	case DropOldest:
+		for {
+			select {
+			case a.queue <- alert:
+				a.enqueued.Add(1)
+				return nil
+			default:
+			}
+
+			// Queue is full, evict the oldest alert and try again
+			select {
+			case evicted := <-a.queue:
+				a.dropped.Add(1)
+				a.am.suppressor.forget(evicted.admission)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case a.queue <- alert:
+		default:
+			a.dropped.Add(1)
+			return ErrQueueFull
+		}
+	}
+
+	a.enqueued.Add(1)
+
+	return nil
+}
+
+// Flush posts every queued alert and waits until delivery is finished
+// or the context is done.
+func (a *asyncAlertManager) Flush(ctx context.Context) error {
+	reply := make(chan error, 1)
+
+	select {
+	case a.flushCh <- flushRequest{ctx: ctx, reply: reply}:
+	case <-a.stopped:
+		return ErrSenderClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-reply:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting alerts, drains the queue and stops the background worker.
+// Alerts still queued when the context is done are lost.
+func (a *asyncAlertManager) Close(ctx context.Context) error {
+	var err error
+
+	a.closeOnce.Do(func() {
+		a.mu.Lock()
+		a.closed = true
+		a.mu.Unlock()
+
+		err = a.Flush(ctx)
+		close(a.done)
+	})
+
+	select {
+	case <-a.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// Stats returns a snapshot of the sender counters.
+func (a *asyncAlertManager) Stats() AsyncStats {
+	return AsyncStats{
+		Enqueued: a.enqueued.Load(),
+		Sent:     a.sent.Load(),
+		Dropped:  a.dropped.Load(),
+		Failed:   a.failed.Load(),
+		Stats:    a.am.Stats(),
+	}
+}
+
+// run is the background worker that batches queued alerts and posts them.
+func (a *asyncAlertManager) run() {
+	defer close(a.stopped)
+
+	ticker := time.NewTicker(a.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]options, 0, a.config.BatchSize)
+
+	for {
+		select {
+		case alert := <-a.queue:
+			batch = append(batch, alert)
+			if len(batch) >= a.config.BatchSize {
+				a.post(context.Background(), batch)
+				batch = batch[:0]
+			}
+
+		case <-ticker.C:
+			if len(batch) > 0 {
+				a.post(context.Background(), batch)
+				batch = batch[:0]
+			}
+
+		case req := <-a.flushCh:
+			req.reply <- a.drain(req.ctx, batch)
+			batch = batch[:0]
+
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// drain posts the pending batch together with everything left in the queue.
+func (a *asyncAlertManager) drain(ctx context.Context, batch []options) error {
+	var errs []error
+
+	for {
+		select {
+		case alert := <-a.queue:
+			batch = append(batch, alert)
+			if len(batch) < a.config.BatchSize {
+				continue
+			}
+
+			if err := a.post(ctx, batch); err != nil {
+				errs = append(errs, err)
+			}
+			batch = batch[:0]
+
+		default:
+			if len(batch) > 0 {
+				if err := a.post(ctx, batch); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			return errors.Join(errs...)
+		}
+	}
+}
+
+func (a *asyncAlertManager) post(ctx context.Context, batch []options) error {
+	if err := a.am.sendHttpRequest(ctx, batch); err != nil {
+		a.failed.Add(uint64(len(batch)))
+		log.Error().Err(err).Int("alerts", len(batch)).Msg("failed to deliver alert batch")
+
+		// Let a retry of these alerts through the dedup window
+		for _, alert := range batch {
+			a.am.suppressor.forget(alert.admission)
+		}
+
+		return err
+	}
+
+	a.sent.Add(uint64(len(batch)))
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package alertmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchRecorder is a mock Alertmanager that records the size of every posted batch.
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches []int
+	started atomic.Int32
+	release chan struct{}
+}
+
+func newBatchRecorder(t *testing.T) (*batchRecorder, *httptest.Server) {
+	rec := &batchRecorder{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec.started.Add(1)
+		if rec.release != nil {
+			<-rec.release
+		}
+
+		var alerts []map[string]any
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&alerts)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		rec.mu.Lock()
+		rec.batches = append(rec.batches, len(alerts))
+		rec.mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	return rec, srv
+}
+
+func (r *batchRecorder) total() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for _, b := range r.batches {
+		n += b
+	}
+
+	return n
+}
+
+func sendTestAlert(am alertmanager.AlertManager) error {
+	return am.Send(
+		alertmanager.WithLabels(alertmanager.Labels{"alertname": "TestAlert"}),
+	)
+}
+
+func Test_AsyncBatchesBySize(t *testing.T) {
+	rec, srv := newBatchRecorder(t)
+
+	am, err := alertmanager.NewAsyncAlertManager(srv.URL, alertmanager.AsyncConfig{
+		BatchSize:     5,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	for range 10 {
+		require.NoError(t, sendTestAlert(am))
+	}
+
+	require.Eventually(t, func() bool { return rec.total() == 10 }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, am.Close(context.Background()))
+	assert.Equal(t, []int{5, 5}, rec.batches)
+	assert.Equal(t, uint64(10), am.Stats().Sent)
+}
+
+func Test_AsyncFlushesByInterval(t *testing.T) {
+	rec, srv := newBatchRecorder(t)
+
+	am, err := alertmanager.NewAsyncAlertManager(srv.URL, alertmanager.AsyncConfig{
+		BatchSize:     100,
+		FlushInterval: 20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer am.Close(context.Background())
+
+	require.NoError(t, sendTestAlert(am))
+	require.NoError(t, sendTestAlert(am))
+
+	require.Eventually(t, func() bool { return rec.total() == 2 }, time.Second, 10*time.Millisecond)
+}
+
+func Test_AsyncCloseDrainsQueue(t *testing.T) {
+	rec, srv := newBatchRecorder(t)
+
+	am, err := alertmanager.NewAsyncAlertManager(srv.URL, alertmanager.AsyncConfig{
+		BatchSize:     4,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	for range 7 {
+		require.NoError(t, sendTestAlert(am))
+	}
+
+	require.NoError(t, am.Close(context.Background()))
+	assert.Equal(t, 7, rec.total())
+
+	err = sendTestAlert(am)
+	require.ErrorIs(t, err, alertmanager.ErrSenderClosed)
+}
+
+func Test_AsyncOverflowPolicies(t *testing.T) {
+	testcases := []struct {
+		name        string
+		policy      alertmanager.OverflowPolicy
+		expectedErr error
+	}{
+		{name: "drop newest rejects incoming alert", policy: alertmanager.DropNewest, expectedErr: alertmanager.ErrQueueFull},
+		{name: "drop oldest accepts incoming alert", policy: alertmanager.DropOldest},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec, srv := newBatchRecorder(t)
+			rec.release = make(chan struct{})
+
+			am, err := alertmanager.NewAsyncAlertManager(srv.URL, alertmanager.AsyncConfig{
+				QueueSize:      2,
+				BatchSize:      1,
+				FlushInterval:  time.Hour,
+				OverflowPolicy: tc.policy,
+			})
+			require.NoError(t, err)
+
+			// The first alert is picked up by the worker which then blocks on the server
+			require.NoError(t, sendTestAlert(am))
+			require.Eventually(t, func() bool {
+				return rec.started.Load() == 1
+			}, time.Second, 10*time.Millisecond)
+
+			require.NoError(t, sendTestAlert(am))
+			require.NoError(t, sendTestAlert(am))
+
+			err = sendTestAlert(am)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, uint64(1), am.Stats().Dropped)
+
+			close(rec.release)
+			require.NoError(t, am.Close(context.Background()))
+			assert.Equal(t, 3, rec.total())
+		})
+	}
+}
+
+func Test_AsyncSendContextBlock(t *testing.T) {
+	rec, srv := newBatchRecorder(t)
+	rec.release = make(chan struct{})
+
+	am, err := alertmanager.NewAsyncAlertManager(srv.URL, alertmanager.AsyncConfig{
+		QueueSize:      1,
+		BatchSize:      1,
+		FlushInterval:  time.Hour,
+		OverflowPolicy: alertmanager.Block,
+	})
+	require.NoError(t, err)
+
+	var sender alertmanager.ContextAlertManager = am
+
+	// Worker blocks on the server with the first alert, the second fills the queue
+	require.NoError(t, sendTestAlert(sender))
+	require.Eventually(t, func() bool {
+		return rec.started.Load() == 1
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, sendTestAlert(sender))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = sender.SendContext(ctx, alertmanager.WithLabels(alertmanager.Labels{"alertname": "TestAlert"}))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, uint64(1), am.Stats().Dropped)
+
+	close(rec.release)
+	require.NoError(t, am.Close(context.Background()))
+	assert.Equal(t, 2, rec.total())
+}
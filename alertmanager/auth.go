@@ -0,0 +1,85 @@
+package alertmanager
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenFile reads a bearer token from a file and reloads it when the file
+// changes, so rotated tokens such as Kubernetes service account tokens are
+// picked up without a restart.
+type tokenFile struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+}
+
+func (t *tokenFile) get() (string, error) {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read bearer token file: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && info.ModTime().Equal(t.modTime) {
+		return t.token, nil
+	}
+
+	b, err := os.ReadFile(t.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read bearer token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("%w: bearer token file %s is empty", ErrInvalidCredentials, t.path)
+	}
+
+	t.token = token
+	t.modTime = info.ModTime()
+
+	return t.token, nil
+}
+
+// tlsConfigOrNew returns the TLS config being built by the options.
+func (am *alertManager) tlsConfigOrNew() *tls.Config {
+	if am.tlsConfig == nil {
+		am.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return am.tlsConfig
+}
+
+// applyTLS installs the TLS config on a copy of the HTTP client transport,
+// so a client passed with WithHTTPClient is never modified.
+func (am *alertManager) applyTLS() error {
+	if am.tlsConfig == nil {
+		return nil
+	}
+
+	var transport *http.Transport
+	switch rt := am.httpClient.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = rt.Clone()
+	default:
+		return fmt.Errorf("%w: TLS options need an *http.Transport, got %T", ErrInvalidTLSConfig, rt)
+	}
+	transport.TLSClientConfig = am.tlsConfig
+
+	client := *am.httpClient
+	client.Transport = transport
+	am.httpClient = &client
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package alertmanager_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AuthOptions(t *testing.T) {
+	var lastReq *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastReq = r
+	}))
+	defer srv.Close()
+
+	t.Run("basic auth", func(t *testing.T) {
+		am, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithBasicAuth("admin", "secret"))
+		require.NoError(t, err)
+		require.NoError(t, sendTestAlert(am))
+
+		user, pass, ok := lastReq.BasicAuth()
+		require.True(t, ok)
+		assert.Equal(t, "admin", user)
+		assert.Equal(t, "secret", pass)
+	})
+
+	t.Run("static bearer token and tenant", func(t *testing.T) {
+		am, err := alertmanager.NewAlertManager(srv.URL,
+			alertmanager.WithBearerToken("token-1"),
+			alertmanager.WithTenantID("team-a"),
+		)
+		require.NoError(t, err)
+		require.NoError(t, sendTestAlert(am))
+
+		assert.Equal(t, "Bearer token-1", lastReq.Header.Get("Authorization"))
+		assert.Equal(t, "team-a", lastReq.Header.Get("X-Scope-OrgID"))
+	})
+
+	t.Run("bearer token file is reloaded", func(t *testing.T) {
+		tokenPath := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(tokenPath, []byte("token-1\n"), 0o600))
+
+		am, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithBearerTokenFile(tokenPath))
+		require.NoError(t, err)
+		require.NoError(t, sendTestAlert(am))
+		assert.Equal(t, "Bearer token-1", lastReq.Header.Get("Authorization"))
+
+		require.NoError(t, os.WriteFile(tokenPath, []byte("token-2\n"), 0o600))
+		require.NoError(t, os.Chtimes(tokenPath, time.Now(), time.Now().Add(time.Minute)))
+
+		require.NoError(t, sendTestAlert(am))
+		assert.Equal(t, "Bearer token-2", lastReq.Header.Get("Authorization"))
+	})
+
+	t.Run("missing bearer token file", func(t *testing.T) {
+		_, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithBearerTokenFile("/not/found"))
+		require.Error(t, err)
+	})
+}
+
+func Test_TLSOptions(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	t.Run("unknown CA is rejected", func(t *testing.T) {
+		am, err := alertmanager.NewAlertManager(srv.URL)
+		require.NoError(t, err)
+		require.Error(t, sendTestAlert(am))
+	})
+
+	t.Run("custom CA is trusted", func(t *testing.T) {
+		caPath := filepath.Join(t.TempDir(), "ca.pem")
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+		require.NoError(t, os.WriteFile(caPath, caPEM, 0o600))
+
+		am, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithCACertFile(caPath))
+		require.NoError(t, err)
+		require.NoError(t, sendTestAlert(am))
+	})
+
+	t.Run("invalid files fail the constructor", func(t *testing.T) {
+		notPEM := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+
+		_, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithCACertFile(notPEM))
+		require.ErrorIs(t, err, alertmanager.ErrInvalidTLSConfig)
+
+		_, err = alertmanager.NewAlertManager(srv.URL, alertmanager.WithClientCert(notPEM, notPEM))
+		require.ErrorIs(t, err, alertmanager.ErrInvalidTLSConfig)
+	})
+}
@@ -0,0 +1,334 @@
+package alertmanager
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/retry"
+)
+
+// ClientOption is a functional option type for configuring the Alertmanager client.
+type ClientOption func(*alertManager)
+
+// setOptionErr records the first option error, the constructor returns it.
+func (am *alertManager) setOptionErr(err error) {
+	if am.optionErr == nil {
+		am.optionErr = err
+	}
+}
+
+// WithHTTPClient returns a ClientOption that replaces the default HTTP client.
+// A nil client is ignored.
+//
+// Example usage:
+//
+//	am, err := NewAlertManager(host, WithHTTPClient(&http.Client{Transport: transport}))
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(am *alertManager) {
+		if client == nil {
+			return
+		}
+		am.httpClient = client
+	}
+}
+
+// WithTimeout returns a ClientOption that sets the timeout of every request to Alertmanager.
+//   - Defaults to 10 seconds if not specified or if an invalid value is provided.
+//
+// The timeout is applied once all options are set, so it also holds for a
+// client passed with WithHTTPClient in any order. It is applied to a copy of
+// the HTTP client, which is never modified.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(am *alertManager) {
+		// If timeout is invalid ignore it and keep the current timeout.
+		if timeout <= 0 {
+			return
+		}
+
+		am.timeout = timeout
+	}
+}
+
+// applyTimeout sets the WithTimeout timeout on a copy of the HTTP client.
+func (am *alertManager) applyTimeout() {
+	if am.timeout <= 0 {
+		return
+	}
+
+	client := *am.httpClient
+	client.Timeout = am.timeout
+	am.httpClient = &client
+}
+
+// WithHeaders returns a ClientOption that adds headers to every request,
+// such as a tenant ID expected by a multi-tenant proxy.
+func WithHeaders(headers map[string]string) ClientOption {
+	return func(am *alertManager) {
+		for key, value := range headers {
+			am.headers.Set(key, value)
+		}
+	}
+}
+
+// WithPeers returns a ClientOption that adds more Alertmanager peers of the
+// same HA cluster. Alerts are posted to every peer concurrently.
+func WithPeers(peers ...string) ClientOption {
+	return func(am *alertManager) {
+		for _, peer := range peers {
+			if peer == "" {
+				continue
+			}
+			am.peers = append(am.peers, peer)
+		}
+	}
+}
+
+// WithQuorum returns a ClientOption that sets how many peers must accept an
+// alert for the delivery to succeed.
+//   - Defaults to 1 if not specified or if an invalid value is provided.
+//
+// A quorum larger than the number of peers is rejected by the constructor.
+func WithQuorum(quorum int) ClientOption {
+	return func(am *alertManager) {
+		if quorum <= 0 {
+			return
+		}
+		am.quorum = quorum
+	}
+}
+
+// WithRefreshInterval returns a ClientOption that sets how often a firing
+// Alert handle is re-posted. Each post keeps the alert active for three intervals.
+//   - Defaults to 1 minute if not specified or if an invalid value is provided.
+func WithRefreshInterval(interval time.Duration) ClientOption {
+	return func(am *alertManager) {
+		if interval <= 0 {
+			return
+		}
+		am.refreshInterval = interval
+	}
+}
+
+// WithDefaultLabels returns a ClientOption that adds labels such as service,
+// env or instance to every alert. Labels set on the alert take precedence.
+func WithDefaultLabels(labels Labels) ClientOption {
+	return func(am *alertManager) {
+		if am.defaultLabels == nil {
+			am.defaultLabels = make(Labels, len(labels))
+		}
+		maps.Copy(am.defaultLabels, labels)
+	}
+}
+
+// WithDefaultAnnotations returns a ClientOption that adds annotations to every alert.
+// Annotations set on the alert take precedence.
+func WithDefaultAnnotations(annotations Annotations) ClientOption {
+	return func(am *alertManager) {
+		if am.defaultAnnotations == nil {
+			am.defaultAnnotations = make(Annotations, len(annotations))
+		}
+		maps.Copy(am.defaultAnnotations, annotations)
+	}
+}
+
+// WithAnnotationTemplates returns a ClientOption that renders annotations from
+// text/template strings for every alert. Templates are executed with TemplateData,
+// so they can use the alert labels and the value passed with WithTemplateData.
+// A template that does not parse makes the constructor fail.
+//
+// Example usage:
+//
+//	am, err := NewAlertManager(host, WithAnnotationTemplates(map[string]string{
+//	    "summary": "{{ .Labels.alertname }} on {{ .Labels.service }}",
+//	    "description": "p99 latency is {{ .Data.Latency }}",
+//	}))
+//
+//	err = am.Send(WithLabels(labels), WithTemplateData(struct{ Latency string }{"1.2s"}))
+func WithAnnotationTemplates(templates map[string]string) ClientOption {
+	return func(am *alertManager) {
+		if am.annotationTemplates == nil {
+			am.annotationTemplates = make(map[string]*template.Template, len(templates))
+		}
+
+		for name, text := range templates {
+			tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+			if err != nil {
+				am.setOptionErr(fmt.Errorf("%w: annotation %q: %v", ErrInvalidTemplate, name, err))
+				continue
+			}
+			am.annotationTemplates[name] = tmpl
+		}
+	}
+}
+
+// WithDedupWindow returns a ClientOption that drops an alert when one with the
+// same labels was sent less than window ago. Resolving an alert is never
+// deduplicated against firing it.
+//   - Disabled if not specified or if an invalid value is provided.
+func WithDedupWindow(window time.Duration) ClientOption {
+	return func(am *alertManager) {
+		if window <= 0 {
+			return
+		}
+		am.suppressor.dedupWindow = window
+	}
+}
+
+// WithRateLimit returns a ClientOption that limits each alertname to perSecond
+// alerts per second with bursts of up to burst alerts. Alerts over the limit are dropped.
+//   - Disabled if not specified or if an invalid value is provided.
+//
+// Example usage:
+//
+//	am, err := NewAlertManager(host,
+//	    WithDedupWindow(time.Minute),
+//	    WithRateLimit(10, 50),
+//	)
+func WithRateLimit(perSecond float64, burst int) ClientOption {
+	return func(am *alertManager) {
+		if perSecond <= 0 || burst <= 0 {
+			return
+		}
+		am.suppressor.rate = perSecond
+		am.suppressor.burst = burst
+	}
+}
+
+// WithRetry returns a ClientOption that retries transient delivery failures,
+// see IsRetryable, with the given policy from the retry package.
+//   - Disabled if not specified or if nil is provided.
+//
+// Example usage:
+//
+//	am, err := NewAlertManager(host, WithRetry(retry.NewRetry(retry.Config{
+//	    MaxAttempts: 5,
+//	    Backoff:     backoff.NewExponentialBackoff(),
+//	})))
+func WithRetry(r retry.Retry) ClientOption {
+	return func(am *alertManager) {
+		if r == nil {
+			return
+		}
+		am.retry = r
+	}
+}
+
+// WithSpool returns a ClientOption that appends the alerts that could not be
+// delivered because of a transient failure, see IsRetryable, to the file at
+// path. They are replayed in order once Alertmanager accepts alerts again, or
+// when ReplaySpool is called.
+// A path that cannot be opened makes the constructor fail.
+func WithSpool(path string) ClientOption {
+	return func(am *alertManager) {
+		s, err := newSpool(path)
+		if err != nil {
+			am.setOptionErr(err)
+			return
+		}
+		am.spool = s
+	}
+}
+
+// WithBasicAuth returns a ClientOption that sends HTTP basic auth credentials
+// with every request.
+func WithBasicAuth(username, password string) ClientOption {
+	return func(am *alertManager) {
+		am.auth = func(req *http.Request) error {
+			req.SetBasicAuth(username, password)
+			return nil
+		}
+	}
+}
+
+// WithBearerToken returns a ClientOption that sends a static bearer token
+// with every request.
+func WithBearerToken(token string) ClientOption {
+	return func(am *alertManager) {
+		am.auth = func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer "+token)
+			return nil
+		}
+	}
+}
+
+// WithBearerTokenFile returns a ClientOption that sends the bearer token stored
+// in the file at path. The file is read again whenever it changes, so token
+// rotation needs no restart. A missing file makes the constructor fail.
+func WithBearerTokenFile(path string) ClientOption {
+	return func(am *alertManager) {
+		tf := &tokenFile{path: path}
+		if _, err := tf.get(); err != nil {
+			am.setOptionErr(err)
+			return
+		}
+
+		am.auth = func(req *http.Request) error {
+			token, err := tf.get()
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			return nil
+		}
+	}
+}
+
+// WithTenantID returns a ClientOption that sets the X-Scope-OrgID header
+// expected by multi-tenant proxies such as Mimir or Cortex.
+func WithTenantID(tenantID string) ClientOption {
+	return WithHeaders(map[string]string{"X-Scope-OrgID": tenantID})
+}
+
+// WithCACertFile returns a ClientOption that trusts the PEM encoded CA
+// certificates in the file at path to verify the Alertmanager server.
+// An unreadable or invalid file makes the constructor fail.
+func WithCACertFile(path string) ClientOption {
+	return func(am *alertManager) {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			am.setOptionErr(fmt.Errorf("%w: %v", ErrInvalidTLSConfig, err))
+			return
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			am.setOptionErr(fmt.Errorf("%w: no certificate found in %s", ErrInvalidTLSConfig, path))
+			return
+		}
+
+		am.tlsConfigOrNew().RootCAs = pool
+	}
+}
+
+// WithClientCert returns a ClientOption that presents the certificate and key
+// stored in PEM files for mutual TLS.
+// Files that cannot be loaded make the constructor fail.
+func WithClientCert(certFile, keyFile string) ClientOption {
+	return func(am *alertManager) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			am.setOptionErr(fmt.Errorf("%w: %v", ErrInvalidTLSConfig, err))
+			return
+		}
+
+		cfg := am.tlsConfigOrNew()
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+}
+
+// WithTLSConfig returns a ClientOption that replaces the TLS configuration of
+// the transport. WithCACertFile and WithClientCert set after it extend it.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(am *alertManager) {
+		if config == nil {
+			return
+		}
+		am.tlsConfig = config.Clone()
+	}
+}
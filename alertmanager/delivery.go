@@ -0,0 +1,76 @@
+package alertmanager
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// IsRetryable reports whether a failed delivery is worth retrying: connection
+// errors, timeouts, 429 and 5xx answers. Client errors such as an invalid
+// payload are not. Whether the caller gave up is up to its context, a request
+// timeout of the HTTP client is retryable.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var clusterErr *ClusterError
+	if errors.As(err, &clusterErr) {
+		for _, f := range clusterErr.Failures {
+			if IsRetryable(f.Err) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.StatusCode)
+	}
+
+	var webhookErr *WebhookError
+	if errors.As(err, &webhookErr) {
+		return isRetryableStatus(webhookErr.StatusCode)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// postWithRetry posts to a single peer, retrying transient failures with the
+// configured retry policy.
+func (am *alertManager) postWithRetry(ctx context.Context, peer string, body []byte) error {
+	return am.withRetry(ctx, func() error {
+		return am.postAlerts(ctx, peer, body)
+	})
+}
+
+// withRetry runs send once, or with the configured retry policy when there is one.
+func (am *alertManager) withRetry(ctx context.Context, send func() error) error {
+	if am.retry == nil {
+		return send()
+	}
+
+	return am.retry.Do(func() error {
+		// Stop retrying once the caller gave up
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return send()
+	}, func(err error) bool {
+		return ctx.Err() == nil && IsRetryable(err)
+	})
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
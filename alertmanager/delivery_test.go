@@ -0,0 +1,291 @@
+package alertmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/DucTran999/shared-pkg/retry"
+	"github.com/DucTran999/shared-pkg/retry/backoff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IsRetryable(t *testing.T) {
+	testcases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil error", err: nil, expected: false},
+		{name: "server error", err: &alertmanager.APIError{StatusCode: http.StatusBadGateway}, expected: true},
+		{name: "too many requests", err: &alertmanager.APIError{StatusCode: http.StatusTooManyRequests}, expected: true},
+		{name: "bad request", err: &alertmanager.APIError{StatusCode: http.StatusBadRequest}, expected: false},
+		{name: "canceled context", err: fmt.Errorf("wrap: %w", context.Canceled), expected: false},
+		{name: "unknown error", err: errors.New("boom"), expected: false},
+		{
+			name:     "client timeout",
+			err:      &url.Error{Op: "Post", URL: "http://am", Err: context.DeadlineExceeded},
+			expected: true,
+		},
+		{
+			name: "cluster with a transient failure",
+			err: &alertmanager.ClusterError{Failures: []alertmanager.PeerError{
+				{Peer: "am-0", Err: &alertmanager.APIError{StatusCode: http.StatusBadRequest}},
+				{Peer: "am-1", Err: &alertmanager.APIError{StatusCode: http.StatusServiceUnavailable}},
+			}},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, alertmanager.IsRetryable(tc.err))
+		})
+	}
+}
+
+func Test_RetryClientTimeout(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	am, err := alertmanager.NewAlertManager(srv.URL,
+		alertmanager.WithTimeout(50*time.Millisecond),
+		alertmanager.WithRetry(newTestRetry()),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, sendTestAlert(am))
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func newTestRetry() retry.Retry {
+	return retry.NewRetry(retry.Config{
+		MaxAttempts: 3,
+		Backoff:     backoff.NewConstantBackoff(backoff.WithBase(time.Millisecond)),
+		Logging:     10,
+	})
+}
+
+func Test_RetryTransientFailure(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	am, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithRetry(newTestRetry()))
+	require.NoError(t, err)
+
+	require.NoError(t, sendTestAlert(am))
+	assert.Equal(t, int32(3), hits.Load())
+}
+
+func Test_SpoolReplaysAfterRecovery(t *testing.T) {
+	var (
+		down     atomic.Bool
+		mu       sync.Mutex
+		received []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var alerts []alertPayload
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&alerts)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		for _, a := range alerts {
+			received = append(received, a.Labels["alertname"])
+		}
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	spoolPath := filepath.Join(t.TempDir(), "alerts.spool")
+
+	am, err := alertmanager.NewAlertManager(srv.URL,
+		alertmanager.WithRetry(newTestRetry()),
+		alertmanager.WithSpool(spoolPath),
+	)
+	require.NoError(t, err)
+
+	down.Store(true)
+	for _, name := range []string{"First", "Second"} {
+		err = am.Send(alertmanager.WithLabels(alertmanager.Labels{"alertname": name}))
+		require.Error(t, err)
+	}
+
+	content, err := os.ReadFile(spoolPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "First")
+
+	down.Store(false)
+	require.NoError(t, am.Send(alertmanager.WithLabels(alertmanager.Labels{"alertname": "Third"})))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 3
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"Third", "First", "Second"}, received)
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(spoolPath + ".replay")
+		return errors.Is(err, os.ErrNotExist)
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, am.ReplaySpool(context.Background()))
+}
+
+func Test_SpoolDropsPermanentFailures(t *testing.T) {
+	type receivedAlert struct {
+		Labels alertmanager.Labels `json:"labels"`
+	}
+
+	var (
+		down     atomic.Bool
+		mu       sync.Mutex
+		received []receivedAlert
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var alerts []receivedAlert
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&alerts)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if alerts[0].Labels["alertname"] == "Invalid" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		received = append(received, alerts...)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	spoolPath := filepath.Join(t.TempDir(), "alerts.spool")
+
+	am, err := alertmanager.NewAlertManager(srv.URL,
+		alertmanager.WithRetry(newTestRetry()),
+		alertmanager.WithSpool(spoolPath),
+	)
+	require.NoError(t, err)
+
+	// Rejected for good, retrying it would block the spool
+	err = am.Send(alertmanager.WithLabels(alertmanager.Labels{"alertname": "Invalid"}))
+	require.Error(t, err)
+
+	content, err := os.ReadFile(spoolPath)
+	require.NoError(t, err)
+	assert.Empty(t, content)
+
+	down.Store(true)
+	require.Error(t, am.Send(alertmanager.WithLabels(alertmanager.Labels{"alertname": "Invalid"})))
+	require.Error(t, am.Send(alertmanager.WithLabels(alertmanager.Labels{"alertname": "Firing"})))
+	down.Store(false)
+
+	require.NoError(t, am.ReplaySpool(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1, "the invalid batch must not block the one behind it")
+	assert.Equal(t, "Firing", received[0].Labels["alertname"])
+
+	// The replayed spool file is only recreated by the next failure
+	content, _ = os.ReadFile(spoolPath)
+	assert.Empty(t, content)
+}
+
+func Test_SpoolKeepsOrderAfterFailedReplay(t *testing.T) {
+	var (
+		down      atomic.Bool
+		replaying atomic.Bool
+		client    atomic.Value
+		mu        sync.Mutex
+		received  []string
+	)
+
+	spoolPath := filepath.Join(t.TempDir(), "alerts.spool")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alerts []alertPayload
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&alerts)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		name := alerts[0].Labels["alertname"]
+
+		if down.Load() {
+			// A batch fails while the spool is replayed
+			if name == "First" && replaying.Load() {
+				am := client.Load().(alertmanager.AlertManager)
+				assert.Error(t, am.Send(alertmanager.WithLabels(alertmanager.Labels{"alertname": "Third"})))
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		mu.Lock()
+		received = append(received, name)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	am, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithSpool(spoolPath))
+	require.NoError(t, err)
+	client.Store(alertmanager.AlertManager(am))
+
+	down.Store(true)
+	for _, name := range []string{"First", "Second"} {
+		require.Error(t, am.Send(alertmanager.WithLabels(alertmanager.Labels{"alertname": name})))
+	}
+
+	replaying.Store(true)
+	require.Error(t, am.ReplaySpool(context.Background()))
+	replaying.Store(false)
+
+	down.Store(false)
+	require.NoError(t, am.ReplaySpool(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"First", "Second", "Third"}, received)
+}
+
+func Test_SpoolInvalidPath(t *testing.T) {
+	_, err := alertmanager.NewAlertManager("http://localhost:9093",
+		alertmanager.WithSpool(filepath.Join(t.TempDir(), "missing", "alerts.spool")),
+	)
+	require.Error(t, err)
+}
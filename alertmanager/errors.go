@@ -3,5 +3,29 @@ package alertmanager
 import "errors"
 
 var (
-	ErrEmptyHost = errors.New("missing host")
+	ErrEmptyHost    = errors.New("missing host")
+	ErrQueueFull    = errors.New("alert queue is full")
+	ErrSenderClosed = errors.New("alert sender is closed")
+
+	ErrInvalidQuorum   = errors.New("quorum exceeds the number of peers")
+	ErrInvalidTemplate = errors.New("invalid annotation template")
+
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidTLSConfig   = errors.New("invalid TLS config")
+
+	ErrMissingLabels      = errors.New("alert must have at least one label")
+	ErrMissingAlertName   = errors.New("alertname label is required")
+	ErrInvalidLabelName   = errors.New("invalid label name")
+	ErrInvalidLabelValue  = errors.New("invalid label value")
+	ErrReservedLabel      = errors.New("label name is reserved")
+	ErrLabelLimitExceeded = errors.New("label limit exceeded")
+
+	ErrInvalidMatcher  = errors.New("invalid matcher")
+	ErrInvalidFilter   = errors.New("invalid alert filter")
+	ErrInvalidSilence  = errors.New("invalid silence")
+	ErrSilenceNotFound = errors.New("silence not found")
+
+	ErrNilSink      = errors.New("sink is nil")
+	ErrNoPeers      = errors.New("no alertmanager peers configured")
+	ErrInvalidRoute = errors.New("invalid route")
 )
@@ -4,17 +4,24 @@ import (
 	"testing"
 
 	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/DucTran999/shared-pkg/alertmanager/alertmanagertest"
 	"github.com/stretchr/testify/require"
 )
 
 func Test_SendAlert(t *testing.T) {
-	am, err := alertmanager.NewAlertManager("http://localhost:9093")
+	// Use "http://localhost:9093" with the docker-compose file to send to a real Alertmanager
+	fake := alertmanagertest.NewServer(t)
+
+	am, err := alertmanager.NewAlertManager(fake.URL)
 	require.NoError(t, err)
 
+	labels := alertmanager.Labels{
+		"alertname": "TestAlert",
+		"level":     "critical",
+	}
+
 	err = am.Send(
-		alertmanager.WithLabels(alertmanager.Labels{
-			"level": "critical",
-		}),
+		alertmanager.WithLabels(labels),
 		alertmanager.WithAnnotations(alertmanager.Annotations{
 			"summary":     "TestAlert",
 			"description": "Example alert",
@@ -22,4 +29,5 @@ func Test_SendAlert(t *testing.T) {
 	)
 
 	require.NoError(t, err)
+	fake.AssertFiring(t, labels)
 }
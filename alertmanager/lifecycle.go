@@ -0,0 +1,249 @@
+package alertmanager
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const DefaultRefreshInterval time.Duration = time.Minute
+
+// Fingerprint returns a stable hash of the label set. Alerts with the same
+// labels share a fingerprint, which is how Alertmanager identifies them.
+func (l Labels) Fingerprint() string {
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := fnv.New64a()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0xff})
+		h.Write([]byte(l[name]))
+		h.Write([]byte{0xff})
+	}
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Alert is a stateful handle on a single alert identified by its labels.
+//
+// Fire posts the alert and keeps refreshing it in the background so it
+// stays active in Alertmanager, Resolve stops the refresh and marks the
+// alert as resolved. Handles with the same labels act on the same alert.
+type Alert struct {
+	am          *alertManager
+	fingerprint string
+
+	mu      sync.Mutex
+	payload options
+	firing  bool
+	stop    chan struct{}
+}
+
+// Alert returns the handle for the alert described by opts. Once a handle is
+// fired, calls with the same labels return it until it is resolved, so an
+// alert fired from several places is deduplicated instead of being restarted.
+// Handles that are never fired are not tracked and cost nothing to drop; when
+// one is fired while another handle with the same labels is firing, the call
+// goes to the firing handle.
+//
+// Example:
+//
+//	alert, err := am.Alert(WithLabels(Labels{"alertname": "QueueBacklog"}))
+//	if err != nil {
+//	    return err
+//	}
+//
+//	err = alert.Fire(ctx)    // condition started
+//	err = alert.Resolve(ctx) // condition cleared
+func (am *alertManager) Alert(opts ...Options) (*Alert, error) {
+	payload, err := am.buildAlert(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := payload.Labels.Fingerprint()
+
+	am.alertsMu.Lock()
+	defer am.alertsMu.Unlock()
+
+	if alert, ok := am.alerts[fingerprint]; ok {
+		return alert, nil
+	}
+
+	return &Alert{
+		am:          am,
+		fingerprint: fingerprint,
+		payload:     payload,
+	}, nil
+}
+
+// Fingerprint returns the fingerprint of the alert labels.
+func (a *Alert) Fingerprint() string {
+	return a.fingerprint
+}
+
+// Firing reports whether the alert has been fired and not resolved yet.
+func (a *Alert) Firing() bool {
+	owner := a.am.owner(a)
+
+	owner.mu.Lock()
+	defer owner.mu.Unlock()
+
+	return owner.firing
+}
+
+// Fire posts the alert and starts refreshing it every refresh interval until
+// Resolve is called. Firing an alert that is already firing only refreshes it
+// and keeps its original start time.
+func (a *Alert) Fire(ctx context.Context) error {
+	// Only the handle that claims the labels refreshes them
+	if owner := a.am.claim(a); owner != a {
+		return owner.Fire(ctx)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.firing {
+		a.payload.StartTime = time.Now()
+	}
+
+	if err := a.post(ctx, a.endsAt()); err != nil {
+		if !a.firing {
+			a.am.unregister(a)
+		}
+		return err
+	}
+
+	if !a.firing {
+		a.firing = true
+		a.stop = make(chan struct{})
+		go a.refreshLoop(a.stop)
+	}
+
+	return nil
+}
+
+// Refresh re-posts a firing alert and pushes its end time forward.
+// It is a no-op when the alert is not firing.
+func (a *Alert) Refresh(ctx context.Context) error {
+	if owner := a.am.owner(a); owner != a {
+		return owner.Refresh(ctx)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.firing {
+		return nil
+	}
+
+	return a.post(ctx, a.endsAt())
+}
+
+// Resolve stops the automatic refresh and posts the alert with endsAt set to now,
+// which resolves it in Alertmanager immediately.
+func (a *Alert) Resolve(ctx context.Context) error {
+	if owner := a.am.owner(a); owner != a {
+		return owner.Resolve(ctx)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.firing {
+		return nil
+	}
+
+	// The refresh loop checks stop under a.mu, so no refresh can follow
+	a.firing = false
+	close(a.stop)
+	a.am.unregister(a)
+
+	// Still under a.mu, so a concurrent Fire is posted after the resolve
+	return a.post(ctx, time.Now())
+}
+
+// claim tracks a as the handle of its labels unless another handle already
+// holds them, and returns the handle that does.
+func (am *alertManager) claim(a *Alert) *Alert {
+	am.alertsMu.Lock()
+	defer am.alertsMu.Unlock()
+
+	if owner, ok := am.alerts[a.fingerprint]; ok {
+		return owner
+	}
+	am.alerts[a.fingerprint] = a
+
+	return a
+}
+
+// owner returns the handle tracked for the labels of a, or a itself.
+func (am *alertManager) owner(a *Alert) *Alert {
+	am.alertsMu.Lock()
+	defer am.alertsMu.Unlock()
+
+	if owner, ok := am.alerts[a.fingerprint]; ok {
+		return owner
+	}
+
+	return a
+}
+
+// unregister forgets a resolved alert so resolved label sets do not pile up.
+func (am *alertManager) unregister(a *Alert) {
+	am.alertsMu.Lock()
+	defer am.alertsMu.Unlock()
+
+	if am.alerts[a.fingerprint] == a {
+		delete(am.alerts, a.fingerprint)
+	}
+}
+
+// endsAt returns the end time that keeps the alert active until a few refreshes are missed.
+func (a *Alert) endsAt() time.Time {
+	return time.Now().Add(3 * a.am.refreshInterval)
+}
+
+// post sends the current payload with the given end time. The caller must hold a.mu.
+func (a *Alert) post(ctx context.Context, endsAt time.Time) error {
+	payload := a.payload
+	payload.EndTime = endsAt
+
+	return a.am.sendHttpRequest(ctx, []options{payload})
+}
+
+func (a *Alert) refreshLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(a.am.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.mu.Lock()
+			select {
+			case <-stop:
+				a.mu.Unlock()
+				return
+			default:
+			}
+
+			if err := a.post(context.Background(), a.endsAt()); err != nil {
+				log.Warn().Err(err).Str("fingerprint", a.fingerprint).Msg("failed to refresh alert")
+			}
+			a.mu.Unlock()
+
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package alertmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type postedAlert struct {
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+}
+
+// alertRecorder is a mock Alertmanager that records every posted alert.
+type alertRecorder struct {
+	mu     sync.Mutex
+	alerts []postedAlert
+}
+
+func newAlertRecorder(t *testing.T) (*alertRecorder, *httptest.Server) {
+	rec := &alertRecorder{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alerts []postedAlert
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&alerts)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		rec.mu.Lock()
+		rec.alerts = append(rec.alerts, alerts...)
+		rec.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+
+	return rec, srv
+}
+
+func (r *alertRecorder) snapshot() []postedAlert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]postedAlert(nil), r.alerts...)
+}
+
+func Test_LabelsFingerprint(t *testing.T) {
+	a := alertmanager.Labels{"alertname": "HighLatency", "service": "api"}
+	b := alertmanager.Labels{"service": "api", "alertname": "HighLatency"}
+	c := alertmanager.Labels{"alertname": "HighLatency", "service": "worker"}
+
+	assert.Equal(t, a.Fingerprint(), b.Fingerprint())
+	assert.NotEqual(t, a.Fingerprint(), c.Fingerprint())
+}
+
+func Test_AlertLifecycle(t *testing.T) {
+	rec, srv := newAlertRecorder(t)
+	ctx := context.Background()
+
+	am, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithRefreshInterval(20*time.Millisecond))
+	require.NoError(t, err)
+
+	labels := alertmanager.Labels{"alertname": "QueueBacklog"}
+
+	alert, err := am.Alert(alertmanager.WithLabels(labels))
+	require.NoError(t, err)
+
+	require.NoError(t, alert.Fire(ctx))
+
+	same, err := am.Alert(alertmanager.WithLabels(labels))
+	require.NoError(t, err)
+	assert.Same(t, alert, same, "same labels must return the firing handle")
+
+	require.NoError(t, same.Fire(ctx))
+	assert.True(t, alert.Firing())
+
+	// Wait for the background refresh
+	require.Eventually(t, func() bool { return len(rec.snapshot()) >= 4 }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, alert.Resolve(ctx))
+	assert.False(t, alert.Firing())
+
+	posted := rec.snapshot()
+	first, last := posted[0], posted[len(posted)-1]
+
+	for _, p := range posted {
+		assert.True(t, p.StartsAt.Equal(first.StartsAt), "refresh must keep the start time")
+	}
+	assert.True(t, first.EndsAt.After(first.StartsAt))
+	assert.False(t, last.EndsAt.After(time.Now()), "resolve must end the alert now")
+
+	// No refresh happens once resolved
+	count := len(posted)
+	time.Sleep(60 * time.Millisecond)
+	assert.Len(t, rec.snapshot(), count)
+
+	// A resolved alert starts over with a new handle
+	next, err := am.Alert(alertmanager.WithLabels(labels))
+	require.NoError(t, err)
+	assert.NotSame(t, alert, next)
+}
+
+func Test_AlertSameLabelsFiredTwice(t *testing.T) {
+	rec, srv := newAlertRecorder(t)
+	ctx := context.Background()
+
+	am, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithRefreshInterval(20*time.Millisecond))
+	require.NoError(t, err)
+
+	labels := alertmanager.Labels{"alertname": "QueueBacklog"}
+
+	// Both handles are taken before either is fired
+	first, err := am.Alert(alertmanager.WithLabels(labels))
+	require.NoError(t, err)
+	second, err := am.Alert(alertmanager.WithLabels(labels))
+	require.NoError(t, err)
+
+	require.NoError(t, first.Fire(ctx))
+	require.NoError(t, second.Fire(ctx))
+	assert.True(t, second.Firing())
+
+	require.NoError(t, second.Resolve(ctx))
+	assert.False(t, first.Firing())
+	assert.False(t, second.Firing())
+
+	posted := rec.snapshot()
+	assert.False(t, posted[len(posted)-1].EndsAt.After(time.Now()), "resolve must end the alert now")
+
+	// No handle keeps refreshing the resolved alert
+	time.Sleep(60 * time.Millisecond)
+	assert.Len(t, rec.snapshot(), len(posted))
+}
@@ -0,0 +1,54 @@
+package alertmanager
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// LogSink writes every alert as a structured log entry. Firing alerts are
+// logged at warn level and resolved ones at info level.
+type LogSink struct {
+	logger zerolog.Logger
+}
+
+// NewLogSink creates a sink that writes alerts to logger.
+//
+// Example:
+//
+//	sink := NewLogSink(log.Logger)
+func NewLogSink(logger zerolog.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+// Notify logs the alerts, it never fails.
+func (s *LogSink) Notify(_ context.Context, alerts []PostableAlert) error {
+	for _, a := range alerts {
+		event := s.logger.Warn()
+		if a.Resolved() {
+			event = s.logger.Info()
+		}
+
+		labels := zerolog.Dict()
+		for name, value := range a.Labels {
+			labels.Str(name, value)
+		}
+
+		annotations := zerolog.Dict()
+		for name, value := range a.Annotations {
+			annotations.Str(name, value)
+		}
+
+		event.
+			Str("status", a.Status()).
+			Str("fingerprint", a.Labels.Fingerprint()).
+			Dict("labels", labels).
+			Dict("annotations", annotations).
+			Time("startsAt", a.StartsAt).
+			Time("endsAt", a.EndsAt).
+			Str("generatorURL", a.GeneratorURL).
+			Msg(a.Labels[AlertNameLabel])
+	}
+
+	return nil
+}
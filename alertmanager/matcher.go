@@ -0,0 +1,159 @@
+package alertmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// MatchType is the comparison a Matcher applies to a label value.
+type MatchType int
+
+const (
+	MatchEqual     MatchType = iota // name="value"
+	MatchNotEqual                   // name!="value"
+	MatchRegexp                     // name=~"value"
+	MatchNotRegexp                  // name!~"value"
+)
+
+func (t MatchType) String() string {
+	switch t {
+	case MatchNotEqual:
+		return "!="
+	case MatchRegexp:
+		return "=~"
+	case MatchNotRegexp:
+		return "!~"
+	default:
+		return "="
+	}
+}
+
+// Matcher selects alerts by comparing one label with a value or a regular expression.
+// Regular expressions are fully anchored, as in Alertmanager.
+type Matcher struct {
+	Name  string
+	Value string
+	Type  MatchType
+}
+
+// String returns the matcher in the Alertmanager filter syntax, e.g. service=~"api|web".
+func (m Matcher) String() string {
+	return fmt.Sprintf("%s%s%q", m.Name, m.Type, m.Value)
+}
+
+// Validate checks the label name and, for regex matchers, the expression.
+func (m Matcher) Validate() error {
+	if !IsValidLabelName(m.Name) {
+		return fmt.Errorf("%w: invalid label name %q", ErrInvalidMatcher, m.Name)
+	}
+
+	if m.isRegex() {
+		if _, err := regexp.Compile("^(?:" + m.Value + ")$"); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidMatcher, m, err)
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether the label set satisfies the matcher.
+// A missing label is treated as an empty value.
+func (m Matcher) Matches(labels Labels) bool {
+	value := labels[m.Name]
+
+	switch m.Type {
+	case MatchNotEqual:
+		return value != m.Value
+	case MatchRegexp, MatchNotRegexp:
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return false
+		}
+		return re.MatchString(value) == (m.Type == MatchRegexp)
+	default:
+		return value == m.Value
+	}
+}
+
+func (m Matcher) isRegex() bool {
+	return m.Type == MatchRegexp || m.Type == MatchNotRegexp
+}
+
+func (m Matcher) isEqual() bool {
+	return m.Type == MatchEqual || m.Type == MatchRegexp
+}
+
+// apiMatcher is the matcher representation of the Alertmanager v2 API.
+type apiMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual *bool  `json:"isEqual,omitempty"`
+}
+
+func (m Matcher) MarshalJSON() ([]byte, error) {
+	isEqual := m.isEqual()
+
+	return json.Marshal(apiMatcher{
+		Name:    m.Name,
+		Value:   m.Value,
+		IsRegex: m.isRegex(),
+		IsEqual: &isEqual,
+	})
+}
+
+func (m *Matcher) UnmarshalJSON(data []byte) error {
+	var raw apiMatcher
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	// isEqual is missing in responses of Alertmanager older than 0.22, where it was always true
+	isEqual := raw.IsEqual == nil || *raw.IsEqual
+
+	m.Name = raw.Name
+	m.Value = raw.Value
+	switch {
+	case raw.IsRegex && isEqual:
+		m.Type = MatchRegexp
+	case raw.IsRegex:
+		m.Type = MatchNotRegexp
+	case isEqual:
+		m.Type = MatchEqual
+	default:
+		m.Type = MatchNotEqual
+	}
+
+	return nil
+}
+
+// matcherPattern splits a matcher in the filter syntax into name, operator and quoted value.
+var matcherPattern = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*(".*")\s*$`)
+
+// ParseMatcher parses a matcher written in the Alertmanager filter syntax,
+// such as service=~"api|web". It is the inverse of Matcher.String.
+func ParseMatcher(s string) (Matcher, error) {
+	parts := matcherPattern.FindStringSubmatch(s)
+	if parts == nil {
+		return Matcher{}, fmt.Errorf("%w: %q", ErrInvalidMatcher, s)
+	}
+
+	value, err := strconv.Unquote(parts[3])
+	if err != nil {
+		return Matcher{}, fmt.Errorf("%w: %q: %v", ErrInvalidMatcher, s, err)
+	}
+
+	m := Matcher{Name: parts[1], Value: value}
+	switch parts[2] {
+	case "!=":
+		m.Type = MatchNotEqual
+	case "=~":
+		m.Type = MatchRegexp
+	case "!~":
+		m.Type = MatchNotRegexp
+	}
+
+	return m, m.Validate()
+}
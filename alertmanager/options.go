@@ -1,15 +1,50 @@
 package alertmanager
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
-type Labels map[string]any
+// Labels identify an alert. Names must match [a-zA-Z_][a-zA-Z0-9_]* and
+// the alertname label is required.
+type Labels map[string]string
 
-type Annotations map[string]any
+// Annotations carry extra information such as a summary or a runbook link.
+type Annotations map[string]string
 
 type options struct {
-	Labels      Labels      `json:"labels"`
-	Annotations Annotations `json:"annotations"`
-	EndTime     time.Time   `json:"endsAt,omitempty"`
+	Labels       Labels      `json:"labels"`
+	Annotations  Annotations `json:"annotations"`
+	StartTime    time.Time   `json:"startsAt,omitempty"`
+	EndTime      time.Time   `json:"endsAt,omitempty"`
+	GeneratorURL string      `json:"generatorURL,omitempty"`
+
+	// templateData is passed to the client annotation templates.
+	templateData any
+
+	// admission is forgotten by the async sender when delivery fails.
+	admission admission
+}
+
+// MarshalJSON leaves the unset times out of the payload, omitempty has no
+// effect on time.Time and would post 0001-01-01 instead.
+func (o options) MarshalJSON() ([]byte, error) {
+	type payload options
+
+	return json.Marshal(struct {
+		payload
+		StartTime *time.Time `json:"startsAt,omitempty"`
+		EndTime   *time.Time `json:"endsAt,omitempty"`
+	}{payload(o), optionalTime(o.StartTime), optionalTime(o.EndTime)})
+}
+
+// optionalTime returns nil for the zero time.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
 }
 
 type Options func(*options)
@@ -31,3 +66,19 @@ func WithDuration(duration time.Duration) Options {
 		o.EndTime = time.Now().Add(duration)
 	}
 }
+
+// WithStartsAt sets the time the alert condition started. When it is not set
+// Alertmanager falls back to the end time, which the client always sets.
+func WithStartsAt(startsAt time.Time) Options {
+	return func(o *options) {
+		o.StartTime = startsAt
+	}
+}
+
+// WithGeneratorURL sets the link back to the entity that produced the alert,
+// such as a dashboard or a runbook.
+func WithGeneratorURL(url string) Options {
+	return func(o *options) {
+		o.GeneratorURL = url
+	}
+}
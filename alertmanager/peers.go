@@ -0,0 +1,88 @@
+package alertmanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PeerError describes a failed delivery to a single Alertmanager peer.
+type PeerError struct {
+	Peer string
+	Err  error
+}
+
+func (e PeerError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Peer, e.Err)
+}
+
+func (e PeerError) Unwrap() error {
+	return e.Err
+}
+
+// ClusterError is returned when fewer peers than the quorum accepted the alerts.
+// It lists every peer that failed, so callers can tell a partial outage
+// from a full one.
+type ClusterError struct {
+	Succeeded int
+	Quorum    int
+	Failures  []PeerError
+}
+
+func (e *ClusterError) Error() string {
+	msgs := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		msgs = append(msgs, f.Error())
+	}
+
+	return fmt.Sprintf("alertmanager quorum not reached (%d/%d peers accepted): %s",
+		e.Succeeded, e.Quorum, strings.Join(msgs, "; "))
+}
+
+// Unwrap exposes the peer errors to errors.Is and errors.As.
+func (e *ClusterError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		errs = append(errs, f)
+	}
+
+	return errs
+}
+
+// fanOut posts the encoded alerts to all peers concurrently.
+func (am *alertManager) fanOut(ctx context.Context, body []byte) error {
+	errs := make([]error, len(am.peers))
+
+	var wg sync.WaitGroup
+	for i, peer := range am.peers {
+		wg.Add(1)
+		go func(i int, peer string) {
+			defer wg.Done()
+			errs[i] = am.postWithRetry(ctx, peer, body)
+		}(i, peer)
+	}
+	wg.Wait()
+
+	clusterErr := &ClusterError{Quorum: am.quorum}
+	for i, err := range errs {
+		if err != nil {
+			clusterErr.Failures = append(clusterErr.Failures, PeerError{Peer: am.peers[i], Err: err})
+			continue
+		}
+		clusterErr.Succeeded++
+	}
+
+	if clusterErr.Succeeded < am.quorum {
+		return clusterErr
+	}
+
+	// Quorum reached, the failed peers catch up through gossip
+	for _, f := range clusterErr.Failures {
+		log.Warn().Str("peer", f.Peer).Err(f.Err).Msg("alertmanager peer rejected alerts")
+	}
+
+	return nil
+}
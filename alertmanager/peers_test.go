@@ -0,0 +1,87 @@
+package alertmanager_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPeer(t *testing.T, status int, hits *atomic.Int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func Test_ClusterFanOut(t *testing.T) {
+	var hits atomic.Int32
+	healthy := newPeer(t, http.StatusOK, &hits)
+	broken := newPeer(t, http.StatusServiceUnavailable, &hits)
+
+	testcases := []struct {
+		name        string
+		peers       []string
+		quorum      int
+		expectedErr bool
+	}{
+		{name: "all peers accept", peers: []string{healthy.URL, healthy.URL, healthy.URL}, quorum: 3},
+		{name: "quorum reached with a failed peer", peers: []string{healthy.URL, healthy.URL, broken.URL}, quorum: 2},
+		{name: "quorum not reached", peers: []string{healthy.URL, broken.URL, broken.URL}, quorum: 2, expectedErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			hits.Store(0)
+
+			am, err := alertmanager.NewAlertManagerCluster(tc.peers, alertmanager.WithQuorum(tc.quorum))
+			require.NoError(t, err)
+
+			err = sendTestAlert(am)
+			assert.Equal(t, int32(len(tc.peers)), hits.Load(), "every peer must receive the alert")
+
+			if !tc.expectedErr {
+				require.NoError(t, err)
+				return
+			}
+
+			var clusterErr *alertmanager.ClusterError
+			require.True(t, errors.As(err, &clusterErr))
+			assert.Equal(t, 1, clusterErr.Succeeded)
+			assert.Equal(t, 2, clusterErr.Quorum)
+			require.Len(t, clusterErr.Failures, 2)
+			assert.Equal(t, broken.URL, clusterErr.Failures[0].Peer)
+		})
+	}
+}
+
+func Test_ClusterWithPeersOption(t *testing.T) {
+	var hits atomic.Int32
+	first := newPeer(t, http.StatusOK, &hits)
+	second := newPeer(t, http.StatusOK, &hits)
+
+	am, err := alertmanager.NewAlertManager(first.URL, alertmanager.WithPeers(second.URL))
+	require.NoError(t, err)
+
+	require.NoError(t, sendTestAlert(am))
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func Test_ClusterInvalidConfig(t *testing.T) {
+	_, err := alertmanager.NewAlertManagerCluster(nil)
+	require.ErrorIs(t, err, alertmanager.ErrEmptyHost)
+
+	_, err = alertmanager.NewAlertManagerCluster([]string{"http://am-0:9093", ""})
+	require.ErrorIs(t, err, alertmanager.ErrEmptyHost)
+
+	_, err = alertmanager.NewAlertManagerCluster([]string{"http://am-0:9093"}, alertmanager.WithQuorum(2))
+	require.ErrorIs(t, err, alertmanager.ErrInvalidQuorum)
+}
@@ -0,0 +1,156 @@
+package alertmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Route sends the alerts matched by all of its matchers to its sinks.
+// A route without matchers matches every alert.
+type Route struct {
+	Matchers []Matcher
+	Sinks    []Sink
+
+	// Continue keeps evaluating the next routes after this one matched.
+	// By default the first matching route wins, like in Alertmanager.
+	Continue bool
+}
+
+// Router is a Sink that dispatches alerts to other sinks by label matchers.
+type Router struct {
+	routes   []Route
+	fallback []Sink
+}
+
+// NewRouter creates a router that evaluates the routes in order. Alerts that
+// match no route go to the fallback sinks, or are dropped when there are none.
+//
+// Example:
+//
+//	router, err := NewRouter([]Route{
+//	    {
+//	        Matchers: []Matcher{{Name: "severity", Value: "critical"}},
+//	        Sinks:    []Sink{pager, slack},
+//	    },
+//	    {
+//	        Matchers: []Matcher{{Name: "team", Value: "payments"}},
+//	        Sinks:    []Sink{slack},
+//	    },
+//	}, NewLogSink(log.Logger))
+func NewRouter(routes []Route, fallback ...Sink) (*Router, error) {
+	for i, route := range routes {
+		if len(route.Sinks) == 0 {
+			return nil, fmt.Errorf("%w: route %d has no sinks", ErrInvalidRoute, i)
+		}
+
+		for _, sink := range route.Sinks {
+			if sink == nil {
+				return nil, fmt.Errorf("%w: route %d: %w", ErrInvalidRoute, i, ErrNilSink)
+			}
+		}
+
+		for _, m := range route.Matchers {
+			if err := m.Validate(); err != nil {
+				return nil, fmt.Errorf("%w: route %d: %w", ErrInvalidRoute, i, err)
+			}
+		}
+	}
+
+	for _, sink := range fallback {
+		if sink == nil {
+			return nil, fmt.Errorf("%w: fallback: %w", ErrInvalidRoute, ErrNilSink)
+		}
+	}
+
+	return &Router{
+		routes:   routes,
+		fallback: fallback,
+	}, nil
+}
+
+// Notify groups the alerts by route and notifies the sinks concurrently.
+// Every failed sink is reported in the returned error.
+//
+// When the router is wrapped by a client with WithRetry, a retry notifies
+// every matched sink again, including the ones that succeeded.
+func (r *Router) Notify(ctx context.Context, alerts []PostableAlert) error {
+	batches := make([][]PostableAlert, len(r.routes))
+	var unmatched []PostableAlert
+
+	for _, alert := range alerts {
+		matched := false
+
+		for i, route := range r.routes {
+			if !matchesAll(route.Matchers, alert.Labels) {
+				continue
+			}
+
+			batches[i] = append(batches[i], alert)
+			matched = true
+
+			if !route.Continue {
+				break
+			}
+		}
+
+		if !matched {
+			unmatched = append(unmatched, alert)
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	notify := func(sink Sink, batch []PostableAlert) {
+		defer wg.Done()
+
+		if err := sink.Notify(ctx, batch); err != nil {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}
+	}
+
+	for i, batch := range batches {
+		if len(batch) == 0 {
+			continue
+		}
+
+		for _, sink := range r.routes[i].Sinks {
+			wg.Add(1)
+			go notify(sink, batch)
+		}
+	}
+
+	if len(unmatched) > 0 {
+		if len(r.fallback) == 0 {
+			log.Debug().Int("alerts", len(unmatched)).Msg("alerts matched no route and were dropped")
+		}
+
+		for _, sink := range r.fallback {
+			wg.Add(1)
+			go notify(sink, unmatched)
+		}
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func matchesAll(matchers []Matcher, labels Labels) bool {
+	for _, m := range matchers {
+		if !m.Matches(labels) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,212 @@
+package alertmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// SilenceState is the state Alertmanager reports for a silence.
+type SilenceState string
+
+const (
+	SilenceActive  SilenceState = "active"
+	SilencePending SilenceState = "pending"
+	SilenceExpired SilenceState = "expired"
+)
+
+// Silence mutes every alert matched by all of its matchers between StartsAt and EndsAt.
+type Silence struct {
+	// ID is assigned by Alertmanager. Setting it on create updates that silence instead.
+	ID       string    `json:"id,omitempty"`
+	Matchers []Matcher `json:"matchers"`
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+
+	// CreatedBy and Comment are required by Alertmanager.
+	CreatedBy string `json:"createdBy"`
+	Comment   string `json:"comment"`
+
+	// Status and UpdatedAt are read only and filled in on list and get.
+	Status    SilenceStatus `json:"status"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// SilenceStatus holds the state of a silence returned by Alertmanager.
+type SilenceStatus struct {
+	State SilenceState `json:"state"`
+}
+
+// postableSilence is the create payload without the read only fields.
+type postableSilence struct {
+	ID        string    `json:"id,omitempty"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
+// Validate checks the silence the same way Alertmanager does before accepting it.
+func (s Silence) Validate() error {
+	if len(s.Matchers) == 0 {
+		return fmt.Errorf("%w: at least one matcher is required", ErrInvalidSilence)
+	}
+
+	matchesEmpty := true
+	for _, m := range s.Matchers {
+		if err := m.Validate(); err != nil {
+			return err
+		}
+
+		if !m.Matches(Labels{}) {
+			matchesEmpty = false
+		}
+	}
+
+	// Such a silence would mute every alert
+	if matchesEmpty {
+		return fmt.Errorf("%w: at least one matcher must not match the empty string", ErrInvalidSilence)
+	}
+
+	if s.EndsAt.IsZero() {
+		return fmt.Errorf("%w: end time is required", ErrInvalidSilence)
+	}
+
+	if !s.StartsAt.IsZero() && !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("%w: end time must be after start time", ErrInvalidSilence)
+	}
+
+	if s.CreatedBy == "" {
+		return fmt.Errorf("%w: createdBy is required", ErrInvalidSilence)
+	}
+
+	if s.Comment == "" {
+		return fmt.Errorf("%w: comment is required", ErrInvalidSilence)
+	}
+
+	return nil
+}
+
+// CreateSilence validates and creates the silence and returns its ID.
+// A zero StartsAt starts the silence immediately.
+//
+// Example:
+//
+//	id, err := am.CreateSilence(ctx, Silence{
+//	    Matchers: []Matcher{
+//	        {Name: "service", Value: "checkout"},
+//	        {Name: "severity", Value: "info|warning", Type: MatchRegexp},
+//	    },
+//	    EndsAt:    time.Now().Add(30 * time.Minute),
+//	    CreatedBy: "deploy-bot",
+//	    Comment:   "rolling out v1.2.3",
+//	})
+func (am *alertManager) CreateSilence(ctx context.Context, silence Silence) (string, error) {
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = time.Now()
+	}
+
+	if err := silence.Validate(); err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		SilenceID string `json:"silenceID"`
+	}
+
+	err := am.doAPI(ctx, http.MethodPost, "/silences", nil, postableSilence{
+		ID:        silence.ID,
+		Matchers:  silence.Matchers,
+		StartsAt:  silence.StartsAt,
+		EndsAt:    silence.EndsAt,
+		CreatedBy: silence.CreatedBy,
+		Comment:   silence.Comment,
+	}, &resp)
+	if err != nil {
+		return "", fmt.Errorf("failed to create silence: %w", err)
+	}
+
+	return resp.SilenceID, nil
+}
+
+// ListSilences returns the silences whose matchers include all the given filters.
+func (am *alertManager) ListSilences(ctx context.Context, filters ...Matcher) ([]Silence, error) {
+	query := url.Values{}
+	for _, f := range filters {
+		if err := f.Validate(); err != nil {
+			return nil, err
+		}
+		query.Add("filter", f.String())
+	}
+
+	var silences []Silence
+	if err := am.doAPI(ctx, http.MethodGet, "/silences", query, nil, &silences); err != nil {
+		return nil, fmt.Errorf("failed to list silences: %w", err)
+	}
+
+	return silences, nil
+}
+
+// GetSilence returns the silence with the given ID or ErrSilenceNotFound.
+func (am *alertManager) GetSilence(ctx context.Context, id string) (*Silence, error) {
+	var silence Silence
+	if err := am.doAPI(ctx, http.MethodGet, "/silence/"+url.PathEscape(id), nil, nil, &silence); err != nil {
+		return nil, silenceError("get", id, err)
+	}
+
+	return &silence, nil
+}
+
+// ExpireSilence ends the silence with the given ID immediately.
+func (am *alertManager) ExpireSilence(ctx context.Context, id string) error {
+	if err := am.doAPI(ctx, http.MethodDelete, "/silence/"+url.PathEscape(id), nil, nil, nil); err != nil {
+		return silenceError("expire", id, err)
+	}
+
+	return nil
+}
+
+// WithSilence creates the silence, runs fn and expires the silence when fn returns,
+// even if fn fails or panics. It is meant for muting alerts during a rollout:
+//
+//	err := am.WithSilence(ctx, silence, func(ctx context.Context) error {
+//	    return deploy(ctx)
+//	})
+//
+// EndsAt still bounds the silence if the process dies before fn returns.
+func (am *alertManager) WithSilence(ctx context.Context, silence Silence, fn func(ctx context.Context) error) (err error) {
+	id, err := am.CreateSilence(ctx, silence)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		timeout := am.httpClient.Timeout
+		if timeout <= 0 {
+			timeout = DefaultClientTimeout
+		}
+
+		// Expire even when ctx is already canceled
+		expireCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+
+		if expireErr := am.ExpireSilence(expireCtx, id); expireErr != nil {
+			err = errors.Join(err, expireErr)
+		}
+	}()
+
+	return fn(ctx)
+}
+
+func silenceError(action, id string, err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", ErrSilenceNotFound, id)
+	}
+
+	return fmt.Errorf("failed to %s silence %s: %w", action, id, err)
+}
@@ -0,0 +1,233 @@
+package alertmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSilenceServer is a mock Alertmanager that stores silences in memory.
+func newSilenceServer(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	silences := map[string]map[string]any{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v2/silences", func(w http.ResponseWriter, r *http.Request) {
+		var s map[string]any
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&s)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		id := fmt.Sprintf("silence-%d", len(silences)+1)
+		s["id"] = id
+		s["status"] = map[string]string{"state": "active"}
+		silences[id] = s
+		mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]string{"silenceID": id})
+	})
+	mux.HandleFunc("GET /api/v2/silences", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, []string{`service="checkout"`}, r.URL.Query()["filter"])
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		list := make([]map[string]any, 0, len(silences))
+		for _, s := range silences {
+			list = append(list, s)
+		}
+		json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("GET /api/v2/silence/{id}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		s, ok := silences[r.PathValue("id")]
+		if !ok {
+			http.Error(w, "silence not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(s)
+	})
+	mux.HandleFunc("DELETE /api/v2/silence/{id}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		s, ok := silences[r.PathValue("id")]
+		if !ok {
+			http.Error(w, "silence not found", http.StatusNotFound)
+			return
+		}
+		s["status"] = map[string]string{"state": "expired"}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func newTestSilence() alertmanager.Silence {
+	return alertmanager.Silence{
+		Matchers: []alertmanager.Matcher{
+			{Name: "service", Value: "checkout"},
+			{Name: "severity", Value: "info|warning", Type: alertmanager.MatchRegexp},
+			{Name: "env", Value: "dev", Type: alertmanager.MatchNotEqual},
+		},
+		EndsAt:    time.Now().Add(time.Hour),
+		CreatedBy: "deploy-bot",
+		Comment:   "rolling out",
+	}
+}
+
+func Test_SilenceValidate(t *testing.T) {
+	testcases := []struct {
+		name    string
+		mutate  func(s *alertmanager.Silence)
+		wantErr error
+	}{
+		{name: "valid silence", mutate: func(s *alertmanager.Silence) {}},
+		{
+			name:    "no matchers",
+			mutate:  func(s *alertmanager.Silence) { s.Matchers = nil },
+			wantErr: alertmanager.ErrInvalidSilence,
+		},
+		{
+			name: "invalid label name",
+			mutate: func(s *alertmanager.Silence) {
+				s.Matchers = []alertmanager.Matcher{{Name: "1service", Value: "api"}}
+			},
+			wantErr: alertmanager.ErrInvalidMatcher,
+		},
+		{
+			name: "invalid regex",
+			mutate: func(s *alertmanager.Silence) {
+				s.Matchers = []alertmanager.Matcher{{Name: "service", Value: "(api", Type: alertmanager.MatchRegexp}}
+			},
+			wantErr: alertmanager.ErrInvalidMatcher,
+		},
+		{
+			name: "matchers match every alert",
+			mutate: func(s *alertmanager.Silence) {
+				s.Matchers = []alertmanager.Matcher{{Name: "service", Value: ".*", Type: alertmanager.MatchRegexp}}
+			},
+			wantErr: alertmanager.ErrInvalidSilence,
+		},
+		{
+			name:    "missing end time",
+			mutate:  func(s *alertmanager.Silence) { s.EndsAt = time.Time{} },
+			wantErr: alertmanager.ErrInvalidSilence,
+		},
+		{
+			name:    "missing comment",
+			mutate:  func(s *alertmanager.Silence) { s.Comment = "" },
+			wantErr: alertmanager.ErrInvalidSilence,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestSilence()
+			tc.mutate(&s)
+
+			err := s.Validate()
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func Test_SilenceLifecycle(t *testing.T) {
+	srv := newSilenceServer(t)
+	ctx := context.Background()
+
+	am, err := alertmanager.NewAlertManager(srv.URL)
+	require.NoError(t, err)
+
+	id, err := am.CreateSilence(ctx, newTestSilence())
+	require.NoError(t, err)
+	require.NotEmpty(t, id)
+
+	silence, err := am.GetSilence(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, alertmanager.SilenceActive, silence.Status.State)
+	assert.Equal(t, newTestSilence().Matchers, silence.Matchers)
+
+	silences, err := am.ListSilences(ctx, alertmanager.Matcher{Name: "service", Value: "checkout"})
+	require.NoError(t, err)
+	require.Len(t, silences, 1)
+
+	require.NoError(t, am.ExpireSilence(ctx, id))
+
+	silence, err = am.GetSilence(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, alertmanager.SilenceExpired, silence.Status.State)
+
+	_, err = am.GetSilence(ctx, "unknown")
+	require.ErrorIs(t, err, alertmanager.ErrSilenceNotFound)
+}
+
+func Test_WithSilence(t *testing.T) {
+	srv := newSilenceServer(t)
+	ctx := context.Background()
+
+	am, err := alertmanager.NewAlertManager(srv.URL)
+	require.NoError(t, err)
+
+	errDeploy := errors.New("deploy failed")
+
+	var silenceID string
+	err = am.WithSilence(ctx, newTestSilence(), func(ctx context.Context) error {
+		silences, err := am.ListSilences(ctx, alertmanager.Matcher{Name: "service", Value: "checkout"})
+		require.NoError(t, err)
+		require.Len(t, silences, 1)
+		assert.Equal(t, alertmanager.SilenceActive, silences[0].Status.State)
+
+		silenceID = silences[0].ID
+		return errDeploy
+	})
+	require.ErrorIs(t, err, errDeploy)
+
+	silence, err := am.GetSilence(ctx, silenceID)
+	require.NoError(t, err)
+	assert.Equal(t, alertmanager.SilenceExpired, silence.Status.State, "silence must be expired after the callback")
+}
+
+func Test_MatcherString(t *testing.T) {
+	matchers := []alertmanager.Matcher{
+		{Name: "service", Value: "api"},
+		{Name: "service", Value: "api", Type: alertmanager.MatchNotEqual},
+		{Name: "service", Value: "api|web", Type: alertmanager.MatchRegexp},
+		{Name: "service", Value: "api|web", Type: alertmanager.MatchNotRegexp},
+	}
+
+	actual := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		actual = append(actual, m.String())
+
+		parsed, err := alertmanager.ParseMatcher(m.String())
+		require.NoError(t, err)
+		assert.Equal(t, m, parsed)
+	}
+
+	assert.Equal(t, `service="api" service!="api" service=~"api|web" service!~"api|web"`, strings.Join(actual, " "))
+
+	_, err := alertmanager.ParseMatcher(`service~"api"`)
+	require.ErrorIs(t, err, alertmanager.ErrInvalidMatcher)
+}
@@ -0,0 +1,112 @@
+package alertmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PostableAlert is an alert as it is handed to a Sink, after the client
+// defaults, templates and validation were applied.
+type PostableAlert struct {
+	Labels       Labels      `json:"labels"`
+	Annotations  Annotations `json:"annotations"`
+	StartsAt     time.Time   `json:"startsAt,omitempty"`
+	EndsAt       time.Time   `json:"endsAt,omitempty"`
+	GeneratorURL string      `json:"generatorURL,omitempty"`
+}
+
+// MarshalJSON leaves the unset times out, see options.MarshalJSON.
+func (a PostableAlert) MarshalJSON() ([]byte, error) {
+	type payload PostableAlert
+
+	return json.Marshal(struct {
+		payload
+		StartsAt *time.Time `json:"startsAt,omitempty"`
+		EndsAt   *time.Time `json:"endsAt,omitempty"`
+	}{payload(a), optionalTime(a.StartsAt), optionalTime(a.EndsAt)})
+}
+
+// Resolved reports whether the alert end time has passed.
+func (a PostableAlert) Resolved() bool {
+	return !a.EndsAt.IsZero() && !a.EndsAt.After(time.Now())
+}
+
+// Status returns "resolved" for resolved alerts and "firing" otherwise.
+func (a PostableAlert) Status() string {
+	if a.Resolved() {
+		return "resolved"
+	}
+
+	return "firing"
+}
+
+// Sink delivers alerts to a destination such as Alertmanager, a webhook or a log.
+// Clients created with NewAlertManager are sinks themselves.
+type Sink interface {
+	Notify(ctx context.Context, alerts []PostableAlert) error
+}
+
+// SinkFunc adapts an ordinary function to the Sink interface.
+type SinkFunc func(ctx context.Context, alerts []PostableAlert) error
+
+func (f SinkFunc) Notify(ctx context.Context, alerts []PostableAlert) error {
+	return f(ctx, alerts)
+}
+
+// NewSinkAlertManager creates a client that hands alerts to sink instead of
+// posting them to Alertmanager. Defaults, templates, suppression, retries and
+// the spool work as with NewAlertManager.
+//
+// The silence and alert query APIs need an Alertmanager, they can be enabled
+// with WithPeers and fail with ErrNoPeers otherwise.
+//
+// Example:
+//
+//	router, err := NewRouter([]Route{
+//	    {Matchers: []Matcher{{Name: "severity", Value: "critical"}}, Sinks: []Sink{slack}},
+//	}, NewLogSink(log.Logger))
+//
+//	am, err := NewSinkAlertManager(router, WithDefaultLabels(Labels{"service": "checkout"}))
+func NewSinkAlertManager(sink Sink, opts ...ClientOption) (*alertManager, error) {
+	if sink == nil {
+		return nil, ErrNilSink
+	}
+
+	return newAlertManager(nil, append([]ClientOption{withSink(sink)}, opts...)...)
+}
+
+func withSink(sink Sink) ClientOption {
+	return func(am *alertManager) {
+		am.sink = sink
+	}
+}
+
+// Notify posts the alerts to Alertmanager, which makes the client usable as
+// the Alertmanager sink of a Router. Client defaults and suppression are not
+// applied, the alerts are expected to be complete already.
+func (am *alertManager) Notify(ctx context.Context, alerts []PostableAlert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+
+	return am.deliver(ctx, body)
+}
+
+// notifySink hands an encoded batch of alerts to the configured sink.
+func (am *alertManager) notifySink(ctx context.Context, body []byte) error {
+	var alerts []PostableAlert
+	if err := json.Unmarshal(body, &alerts); err != nil {
+		return fmt.Errorf("failed to decode alert batch: %w", err)
+	}
+
+	return am.withRetry(ctx, func() error {
+		return am.sink.Notify(ctx, alerts)
+	})
+}
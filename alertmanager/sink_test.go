@@ -0,0 +1,269 @@
+package alertmanager_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/DucTran999/shared-pkg/alertmanager/alertmanagertest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sinkRecorder is a Sink that keeps every alert it is notified with.
+type sinkRecorder struct {
+	mu     sync.Mutex
+	alerts []alertmanager.PostableAlert
+}
+
+func (r *sinkRecorder) Notify(_ context.Context, alerts []alertmanager.PostableAlert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.alerts = append(r.alerts, alerts...)
+
+	return nil
+}
+
+func (r *sinkRecorder) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.alerts))
+	for _, a := range r.alerts {
+		names = append(names, a.Labels["alertname"])
+	}
+
+	return names
+}
+
+func newAlert(name string, labels alertmanager.Labels) alertmanager.PostableAlert {
+	l := alertmanager.Labels{"alertname": name}
+	for k, v := range labels {
+		l[k] = v
+	}
+
+	return alertmanager.PostableAlert{Labels: l, EndsAt: time.Now().Add(time.Minute)}
+}
+
+func Test_SinkAlertManager(t *testing.T) {
+	rec := &sinkRecorder{}
+
+	am, err := alertmanager.NewSinkAlertManager(rec,
+		alertmanager.WithDefaultLabels(alertmanager.Labels{"service": "checkout"}),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, sendTestAlert(am))
+
+	require.Len(t, rec.alerts, 1)
+	assert.Equal(t, alertmanager.Labels{"alertname": "TestAlert", "service": "checkout"}, rec.alerts[0].Labels)
+	assert.Equal(t, "firing", rec.alerts[0].Status())
+
+	_, err = am.ListSilences(context.Background())
+	require.ErrorIs(t, err, alertmanager.ErrNoPeers)
+
+	_, err = alertmanager.NewSinkAlertManager(nil)
+	require.ErrorIs(t, err, alertmanager.ErrNilSink)
+}
+
+func Test_SinkAlertManagerRetries(t *testing.T) {
+	var calls atomic.Int32
+	sink := alertmanager.SinkFunc(func(ctx context.Context, alerts []alertmanager.PostableAlert) error {
+		if calls.Add(1) == 1 {
+			return &alertmanager.WebhookError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	})
+
+	am, err := alertmanager.NewSinkAlertManager(sink, alertmanager.WithRetry(newTestRetry()))
+	require.NoError(t, err)
+
+	require.NoError(t, sendTestAlert(am))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func Test_Router(t *testing.T) {
+	pager, slack, fallback := &sinkRecorder{}, &sinkRecorder{}, &sinkRecorder{}
+
+	router, err := alertmanager.NewRouter([]alertmanager.Route{
+		{
+			Matchers: []alertmanager.Matcher{{Name: "severity", Value: "critical"}},
+			Sinks:    []alertmanager.Sink{pager},
+			Continue: true,
+		},
+		{
+			Matchers: []alertmanager.Matcher{{Name: "team", Value: "payments|checkout", Type: alertmanager.MatchRegexp}},
+			Sinks:    []alertmanager.Sink{slack},
+		},
+		{
+			Matchers: []alertmanager.Matcher{{Name: "team", Value: "checkout"}},
+			Sinks:    []alertmanager.Sink{pager},
+		},
+	}, fallback)
+	require.NoError(t, err)
+
+	err = router.Notify(context.Background(), []alertmanager.PostableAlert{
+		newAlert("PaymentsDown", alertmanager.Labels{"severity": "critical", "team": "payments"}),
+		newAlert("CheckoutSlow", alertmanager.Labels{"severity": "warning", "team": "checkout"}),
+		newAlert("DiskFull", alertmanager.Labels{"severity": "warning", "team": "infra"}),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"PaymentsDown"}, pager.names(), "first match wins unless Continue is set")
+	assert.Equal(t, []string{"PaymentsDown", "CheckoutSlow"}, slack.names())
+	assert.Equal(t, []string{"DiskFull"}, fallback.names())
+}
+
+func Test_RouterReportsSinkErrors(t *testing.T) {
+	errSink := alertmanager.SinkFunc(func(ctx context.Context, alerts []alertmanager.PostableAlert) error {
+		return &alertmanager.WebhookError{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"}
+	})
+	rec := &sinkRecorder{}
+
+	router, err := alertmanager.NewRouter([]alertmanager.Route{
+		{Sinks: []alertmanager.Sink{errSink, rec}},
+	})
+	require.NoError(t, err)
+
+	err = router.Notify(context.Background(), []alertmanager.PostableAlert{newAlert("TestAlert", nil)})
+	require.ErrorContains(t, err, "502 Bad Gateway")
+	assert.True(t, alertmanager.IsRetryable(err))
+	assert.Equal(t, []string{"TestAlert"}, rec.names(), "a failing sink must not block the others")
+}
+
+func Test_NewRouterValidation(t *testing.T) {
+	testcases := []struct {
+		name   string
+		routes []alertmanager.Route
+	}{
+		{name: "route without sinks", routes: []alertmanager.Route{{}}},
+		{name: "nil sink", routes: []alertmanager.Route{{Sinks: []alertmanager.Sink{nil}}}},
+		{
+			name: "invalid matcher",
+			routes: []alertmanager.Route{{
+				Matchers: []alertmanager.Matcher{{Name: "team", Value: "(payments", Type: alertmanager.MatchRegexp}},
+				Sinks:    []alertmanager.Sink{&sinkRecorder{}},
+			}},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := alertmanager.NewRouter(tc.routes)
+			require.ErrorIs(t, err, alertmanager.ErrInvalidRoute)
+		})
+	}
+}
+
+func Test_AlertManagerSink(t *testing.T) {
+	fake := alertmanagertest.NewServer(t)
+
+	upstream, err := alertmanager.NewAlertManager(fake.URL)
+	require.NoError(t, err)
+
+	router, err := alertmanager.NewRouter(nil, upstream)
+	require.NoError(t, err)
+
+	am, err := alertmanager.NewSinkAlertManager(router)
+	require.NoError(t, err)
+
+	require.NoError(t, sendTestAlert(am))
+	fake.AssertFiring(t, alertmanager.Labels{"alertname": "TestAlert"})
+}
+
+func Test_WebhookSink(t *testing.T) {
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var err error
+		body, err = io.ReadAll(r.Body)
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	t.Run("alertmanager webhook format", func(t *testing.T) {
+		sink, err := alertmanager.NewWebhookSink(srv.URL,
+			alertmanager.WithWebhookHeaders(map[string]string{"Authorization": "Bearer secret"}),
+		)
+		require.NoError(t, err)
+
+		resolved := newAlert("DiskFull", nil)
+		resolved.EndsAt = time.Now().Add(-time.Second)
+
+		require.NoError(t, sink.Notify(context.Background(), []alertmanager.PostableAlert{
+			newAlert("HighLatency", alertmanager.Labels{"service": "api"}),
+			resolved,
+		}))
+
+		var msg alertmanager.WebhookMessage
+		require.NoError(t, json.Unmarshal(body, &msg))
+		assert.Equal(t, "firing", msg.Status)
+		require.Len(t, msg.Alerts, 2)
+		assert.Equal(t, "firing", msg.Alerts[0].Status)
+		assert.Equal(t, "resolved", msg.Alerts[1].Status)
+		assert.Equal(t, alertmanager.Labels{"alertname": "HighLatency", "service": "api"}.Fingerprint(), msg.Alerts[0].Fingerprint)
+	})
+
+	t.Run("slack format", func(t *testing.T) {
+		sink, err := alertmanager.NewWebhookSink(srv.URL,
+			alertmanager.WithWebhookHeaders(map[string]string{"Authorization": "Bearer secret"}),
+			alertmanager.WithWebhookEncoder(alertmanager.SlackEncoder),
+		)
+		require.NoError(t, err)
+
+		alert := newAlert("HighLatency", alertmanager.Labels{"service": "checkout", "severity": "critical"})
+		alert.Annotations = alertmanager.Annotations{"summary": "p99 above 2s"}
+
+		require.NoError(t, sink.Notify(context.Background(), []alertmanager.PostableAlert{alert}))
+
+		var msg map[string]string
+		require.NoError(t, json.Unmarshal(body, &msg))
+		assert.Equal(t, "[FIRING] HighLatency: p99 above 2s (service=checkout, severity=critical)", msg["text"])
+	})
+
+	t.Run("non-2xx answer", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+		}))
+		defer failing.Close()
+
+		sink, err := alertmanager.NewWebhookSink(failing.URL)
+		require.NoError(t, err)
+
+		err = sink.Notify(context.Background(), []alertmanager.PostableAlert{newAlert("TestAlert", nil)})
+
+		var webhookErr *alertmanager.WebhookError
+		require.ErrorAs(t, err, &webhookErr)
+		assert.Equal(t, http.StatusBadRequest, webhookErr.StatusCode)
+		assert.Equal(t, "invalid payload", webhookErr.Message)
+		assert.False(t, alertmanager.IsRetryable(err))
+	})
+}
+
+func Test_LogSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := alertmanager.NewLogSink(zerolog.New(&buf))
+
+	require.NoError(t, sink.Notify(context.Background(), []alertmanager.PostableAlert{
+		newAlert("HighLatency", alertmanager.Labels{"service": "api"}),
+	}))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "warn", entry["level"])
+	assert.Equal(t, "HighLatency", entry["message"])
+	assert.Equal(t, "firing", entry["status"])
+	assert.Equal(t, map[string]any{"alertname": "HighLatency", "service": "api"}, entry["labels"])
+}
@@ -0,0 +1,260 @@
+package alertmanager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// spool is an append-only file of alert batches that could not be delivered.
+// Each line holds one JSON encoded batch.
+type spool struct {
+	path string
+
+	mu        sync.Mutex
+	pending   atomic.Bool
+	replaying atomic.Bool
+}
+
+func newSpool(path string) (*spool, error) {
+	s := &spool{path: path}
+
+	// Create the file up front so a bad path fails at construction
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open alert spool: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open alert spool: %w", err)
+	}
+	f.Close()
+
+	// Batches left by a previous run, or by a replay interrupted by a crash
+	_, replayErr := os.Stat(s.replayPath())
+	s.pending.Store(info.Size() > 0 || replayErr == nil)
+
+	return s, nil
+}
+
+// replayPath is where the spool is moved while it is replayed, so new
+// failures can keep appending to path.
+func (s *spool) replayPath() string {
+	return s.path + ".replay"
+}
+
+func (s *spool) append(batches ...[]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.appendLocked(batches...)
+}
+
+func (s *spool) appendLocked(batches ...[]byte) error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if err := writeBatches(f, batches); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	s.pending.Store(true)
+
+	return nil
+}
+
+// requeueLocked puts the batches that were not replayed back in front of the
+// ones spooled during the replay, so the spool keeps the send order.
+func (s *spool) requeueLocked(batches [][]byte) error {
+	spooled, err := readBatches(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	batches = append(batches, spooled...)
+
+	tmpPath := s.path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if err := writeBatches(f, batches); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return err
+	}
+
+	s.pending.Store(len(batches) > 0)
+
+	return nil
+}
+
+// replay sends every spooled batch in order and stops at the first failure
+// worth retrying, see IsRetryable. Batches that can never be delivered, such
+// as the ones rejected with a 4xx answer, are logged and dropped so they do
+// not block the batches behind them. The batches that were not delivered are
+// put back in front of the spool.
+func (s *spool) replay(ctx context.Context, send func(body []byte) error) error {
+	if !s.replaying.CompareAndSwap(false, true) {
+		return nil
+	}
+	defer s.replaying.Store(false)
+
+	// Resume a replay interrupted by a crash before taking new batches
+	s.mu.Lock()
+	if _, err := os.Stat(s.replayPath()); errors.Is(err, os.ErrNotExist) {
+		if err := os.Rename(s.path, s.replayPath()); err != nil {
+			s.mu.Unlock()
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		s.pending.Store(false)
+	}
+	s.mu.Unlock()
+
+	batches, err := readBatches(s.replayPath())
+	if err != nil {
+		return err
+	}
+
+	var sendErr error
+	for i, batch := range batches {
+		err := send(batch)
+		if err == nil {
+			continue
+		}
+
+		if !isPermanent(ctx, err) {
+			sendErr = err
+			batches = batches[i:]
+			break
+		}
+
+		log.Error().Err(err).RawJSON("alerts", batch).Msg("dropping spooled alerts that cannot be delivered")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sendErr != nil {
+		if err := s.requeueLocked(batches); err != nil {
+			return errors.Join(sendErr, err)
+		}
+	}
+
+	if err := os.Remove(s.replayPath()); err != nil {
+		return errors.Join(sendErr, err)
+	}
+
+	return sendErr
+}
+
+func writeBatches(f *os.File, batches [][]byte) error {
+	var buf bytes.Buffer
+	for _, b := range batches {
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+
+	_, err := f.Write(buf.Bytes())
+
+	return err
+}
+
+func readBatches(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var batches [][]byte
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		batches = append(batches, bytes.Clone(scanner.Bytes()))
+	}
+
+	return batches, scanner.Err()
+}
+
+// isPermanent reports whether a failed delivery can never succeed, so the
+// batch is not worth spooling. A canceled ctx says nothing about the batch.
+func isPermanent(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && !IsRetryable(err)
+}
+
+// spoolBatch keeps a batch that could not be delivered for a later replay.
+// Batches rejected for good, see IsRetryable, are not spooled.
+func (am *alertManager) spoolBatch(ctx context.Context, body []byte, cause error) {
+	// The caller gave up, this is not a delivery failure
+	if am.spool == nil || ctx.Err() != nil {
+		return
+	}
+
+	if isPermanent(ctx, cause) {
+		log.Error().Err(cause).Msg("alerts cannot be delivered, not spooling them")
+		return
+	}
+
+	if err := am.spool.append(body); err != nil {
+		log.Error().Err(err).AnErr("cause", cause).Msg("failed to spool undelivered alerts")
+		return
+	}
+
+	log.Warn().Err(cause).Str("spool", am.spool.path).Msg("alerts spooled for replay")
+}
+
+func (am *alertManager) replaySpoolInBackground() {
+	if am.spool == nil || !am.spool.pending.Load() {
+		return
+	}
+
+	go func() {
+		if err := am.ReplaySpool(context.Background()); err != nil {
+			log.Warn().Err(err).Msg("failed to replay spooled alerts")
+		}
+	}()
+}
+
+// ReplaySpool delivers the batches spooled while Alertmanager was unreachable.
+// It runs automatically after the next successful send, but can also be called
+// on startup. It is a no-op when no spool is configured.
+func (am *alertManager) ReplaySpool(ctx context.Context) error {
+	if am.spool == nil {
+		return nil
+	}
+
+	return am.spool.replay(ctx, func(body []byte) error {
+		return am.deliver(ctx, body)
+	})
+}
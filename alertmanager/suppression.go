@@ -0,0 +1,173 @@
+package alertmanager
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of the alerts suppressed on the client side.
+type Stats struct {
+	Deduplicated uint64 // Alerts dropped because the same labels were sent within the dedup window
+	RateLimited  uint64 // Alerts dropped because their alertname ran out of tokens
+}
+
+// suppressor protects Alertmanager from alert floods with a dedup window keyed by
+// label fingerprint and a token bucket per alertname.
+type suppressor struct {
+	dedupWindow time.Duration
+	rate        float64
+	burst       int
+
+	mu        sync.Mutex
+	lastSent  map[string]time.Time
+	lastSweep time.Time
+	buckets   map[string]*tokenBucket
+
+	deduplicated atomic.Uint64
+	rateLimited  atomic.Uint64
+}
+
+// admission is the dedup entry recorded for an admitted alert, so it can be
+// forgotten when the alert is not delivered.
+type admission struct {
+	key string
+	at  time.Time
+}
+
+// admit reports whether the alert should be sent. The alert only counts for
+// the dedup window once it passed the rate limiter, and the caller must call
+// forget with the returned admission when it fails to deliver it, so a retry
+// is not dropped as a duplicate. It is safe for concurrent use.
+func (s *suppressor) admit(alert options) (admission, bool) {
+	if s.dedupWindow <= 0 && s.rate <= 0 {
+		return admission{}, true
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	var key, opposite string
+	if s.dedupWindow > 0 {
+		// A resolve must get through even right after the alert fired
+		key = alert.Labels.Fingerprint()
+		opposite = key + ":resolved"
+		if !alert.EndTime.IsZero() && !alert.EndTime.After(now) {
+			key, opposite = opposite, key
+		}
+
+		if last, ok := s.lastSent[key]; ok && now.Sub(last) < s.dedupWindow {
+			s.deduplicated.Add(1)
+			return admission{}, false
+		}
+	}
+
+	if s.rate > 0 {
+		name := alert.Labels[AlertNameLabel]
+		bucket, ok := s.buckets[name]
+		if !ok {
+			bucket = &tokenBucket{tokens: float64(s.burst), last: now}
+			s.buckets[name] = bucket
+		}
+
+		if !bucket.take(now, s.rate, s.burst) {
+			s.rateLimited.Add(1)
+			return admission{}, false
+		}
+	}
+
+	if key == "" {
+		return admission{}, true
+	}
+
+	// A fire after a resolve, or the other way round, is never a duplicate
+	delete(s.lastSent, opposite)
+	s.lastSent[key] = now
+
+	return admission{key: key, at: now}, true
+}
+
+// forget removes the dedup entry of an alert that was not delivered, unless
+// the same alert was admitted again since.
+func (s *suppressor) forget(a admission) {
+	if a.key == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.lastSent[a.key]; ok && last.Equal(a.at) {
+		delete(s.lastSent, a.key)
+	}
+}
+
+// sweep drops dedup entries older than the window and the token buckets that
+// are full again, at most once per window or refill time.
+// The caller must hold s.mu.
+func (s *suppressor) sweep(now time.Time) {
+	refill := s.refillTime()
+	if now.Sub(s.lastSweep) < max(s.dedupWindow, refill) {
+		return
+	}
+	s.lastSweep = now
+
+	for key, last := range s.lastSent {
+		if now.Sub(last) >= s.dedupWindow {
+			delete(s.lastSent, key)
+		}
+	}
+
+	// A full bucket is the same as a new one
+	for name, bucket := range s.buckets {
+		if now.Sub(bucket.last) >= refill {
+			delete(s.buckets, name)
+		}
+	}
+}
+
+// refillTime returns how long an empty token bucket takes to fill up.
+func (s *suppressor) refillTime() time.Duration {
+	if s.rate <= 0 {
+		return 0
+	}
+
+	return time.Duration(float64(s.burst) / s.rate * float64(time.Second))
+}
+
+func (s *suppressor) stats() Stats {
+	return Stats{
+		Deduplicated: s.deduplicated.Load(),
+		RateLimited:  s.rateLimited.Load(),
+	}
+}
+
+// tokenBucket refills at rate tokens per second up to burst tokens.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) take(now time.Time, rate float64, burst int) bool {
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+
+	return true
+}
+
+// Stats returns the counters of alerts suppressed by the dedup window and the rate limiter.
+func (am *alertManager) Stats() Stats {
+	return am.suppressor.stats()
+}
@@ -0,0 +1,138 @@
+package alertmanager_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DedupWindow(t *testing.T) {
+	var hits atomic.Int32
+	srv := newPeer(t, http.StatusOK, &hits)
+
+	am, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithDedupWindow(50*time.Millisecond))
+	require.NoError(t, err)
+
+	labels := alertmanager.Labels{"alertname": "DependencyDown", "dependency": "payments"}
+	for range 100 {
+		require.NoError(t, am.Send(alertmanager.WithLabels(labels)))
+	}
+	assert.Equal(t, int32(1), hits.Load())
+	assert.Equal(t, uint64(99), am.Stats().Deduplicated)
+
+	// Resolving is not a duplicate of firing
+	require.NoError(t, am.Send(alertmanager.WithLabels(labels), alertmanager.WithDuration(0)))
+	assert.Equal(t, int32(2), hits.Load())
+
+	// Other labels have their own window
+	require.NoError(t, am.Send(alertmanager.WithLabels(alertmanager.Labels{"alertname": "DependencyDown", "dependency": "search"})))
+	assert.Equal(t, int32(3), hits.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, am.Send(alertmanager.WithLabels(labels)))
+	assert.Equal(t, int32(4), hits.Load())
+}
+
+func Test_DedupWindowFireResolveFire(t *testing.T) {
+	var hits atomic.Int32
+	srv := newPeer(t, http.StatusOK, &hits)
+
+	am, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithDedupWindow(time.Minute))
+	require.NoError(t, err)
+
+	labels := alertmanager.Labels{"alertname": "DependencyDown", "dependency": "payments"}
+
+	require.NoError(t, am.Send(alertmanager.WithLabels(labels)))
+	require.NoError(t, am.Send(alertmanager.WithLabels(labels), alertmanager.WithDuration(0)))
+
+	// The alert fires again within the window, it must not be taken for the first fire
+	require.NoError(t, am.Send(alertmanager.WithLabels(labels)))
+	assert.Equal(t, int32(3), hits.Load())
+
+	require.NoError(t, am.Send(alertmanager.WithLabels(labels), alertmanager.WithDuration(0)))
+	assert.Equal(t, int32(4), hits.Load())
+	assert.Zero(t, am.Stats().Deduplicated)
+}
+
+func Test_RateLimitPerAlertName(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	am, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithRateLimit(1, 5))
+	require.NoError(t, err)
+
+	for i := range 20 {
+		require.NoError(t, am.Send(alertmanager.WithLabels(alertmanager.Labels{
+			"alertname": "Flapping",
+			"attempt":   string(rune('a' + i)),
+		})))
+	}
+	assert.Equal(t, int32(5), hits.Load(), "only the burst must get through")
+	assert.Equal(t, uint64(15), am.Stats().RateLimited)
+
+	// Another alertname has its own bucket
+	require.NoError(t, sendTestAlert(am))
+	assert.Equal(t, int32(6), hits.Load())
+}
+
+func Test_DedupWindowLetsRetriesThrough(t *testing.T) {
+	var (
+		hits   atomic.Int32
+		failed atomic.Bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if failed.CompareAndSwap(false, true) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	am, err := alertmanager.NewAlertManager(srv.URL, alertmanager.WithDedupWindow(time.Minute))
+	require.NoError(t, err)
+
+	labels := alertmanager.Labels{"alertname": "DependencyDown", "dependency": "payments"}
+	require.Error(t, am.Send(alertmanager.WithLabels(labels)))
+
+	// The failed alert was never delivered, so the retry is not a duplicate
+	require.NoError(t, am.Send(alertmanager.WithLabels(labels)))
+	assert.Equal(t, int32(2), hits.Load())
+	assert.Zero(t, am.Stats().Deduplicated)
+
+	require.NoError(t, am.Send(alertmanager.WithLabels(labels)))
+	assert.Equal(t, int32(2), hits.Load())
+	assert.Equal(t, uint64(1), am.Stats().Deduplicated)
+}
+
+func Test_DedupWindowIgnoresRateLimitedAlerts(t *testing.T) {
+	var hits atomic.Int32
+	srv := newPeer(t, http.StatusOK, &hits)
+
+	am, err := alertmanager.NewAlertManager(srv.URL,
+		alertmanager.WithDedupWindow(time.Minute),
+		alertmanager.WithRateLimit(20, 1),
+	)
+	require.NoError(t, err)
+
+	first := alertmanager.Labels{"alertname": "Flapping", "instance": "a"}
+	second := alertmanager.Labels{"alertname": "Flapping", "instance": "b"}
+
+	require.NoError(t, am.Send(alertmanager.WithLabels(first)))
+	require.NoError(t, am.Send(alertmanager.WithLabels(second)))
+	assert.Equal(t, uint64(1), am.Stats().RateLimited)
+
+	// Once the bucket refilled, the rate limited alert is not a duplicate
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, am.Send(alertmanager.WithLabels(second)))
+	assert.Equal(t, int32(2), hits.Load())
+	assert.Zero(t, am.Stats().Deduplicated)
+}
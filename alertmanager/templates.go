@@ -0,0 +1,59 @@
+package alertmanager
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// TemplateData is the value annotation templates are executed with.
+//
+// Example template:
+//
+//	{{ .Labels.service }} p99 latency is {{ .Data.Latency }}
+type TemplateData struct {
+	// Labels are the final labels of the alert, defaults included.
+	Labels Labels
+
+	// Data is the per-call value passed with WithTemplateData.
+	Data any
+}
+
+// WithTemplateData sets the data the client annotation templates are rendered with.
+func WithTemplateData(data any) Options {
+	return func(o *options) {
+		o.templateData = data
+	}
+}
+
+// applyDefaults merges the client default labels and annotations into the alert
+// and renders the annotation templates. The precedence from lowest to highest is
+// default annotations, templates, then the annotations set on the alert.
+func (am *alertManager) applyDefaults(cfg *options) error {
+	if len(am.defaultLabels) > 0 {
+		labels := maps.Clone(am.defaultLabels)
+		maps.Copy(labels, cfg.Labels)
+		cfg.Labels = labels
+	}
+
+	if len(am.defaultAnnotations) == 0 && len(am.annotationTemplates) == 0 {
+		return nil
+	}
+
+	annotations := make(Annotations, len(am.defaultAnnotations)+len(am.annotationTemplates)+len(cfg.Annotations))
+	maps.Copy(annotations, am.defaultAnnotations)
+
+	data := TemplateData{Labels: cfg.Labels, Data: cfg.templateData}
+	for name, tmpl := range am.annotationTemplates {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			return fmt.Errorf("%w: annotation %q: %v", ErrInvalidTemplate, name, err)
+		}
+		annotations[name] = sb.String()
+	}
+
+	maps.Copy(annotations, cfg.Annotations)
+	cfg.Annotations = annotations
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package alertmanager_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type alertPayload struct {
+	Labels      alertmanager.Labels      `json:"labels"`
+	Annotations alertmanager.Annotations `json:"annotations"`
+}
+
+func Test_DefaultsAndTemplates(t *testing.T) {
+	var received []alertPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&received)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}))
+	defer srv.Close()
+
+	defaults := alertmanager.Labels{"service": "checkout", "env": "prod"}
+
+	am, err := alertmanager.NewAlertManager(srv.URL,
+		alertmanager.WithDefaultLabels(defaults),
+		alertmanager.WithDefaultAnnotations(alertmanager.Annotations{
+			"runbook_url": "http://wiki/runbook",
+			"summary":     "overridden by the template",
+		}),
+		alertmanager.WithAnnotationTemplates(map[string]string{
+			"summary":     "{{ .Labels.alertname }} on {{ .Labels.service }}",
+			"description": "p99 latency is {{ .Data.Latency }}",
+		}),
+	)
+	require.NoError(t, err)
+
+	err = am.Send(
+		alertmanager.WithLabels(alertmanager.Labels{"alertname": "HighLatency", "env": "staging"}),
+		alertmanager.WithAnnotations(alertmanager.Annotations{"runbook_url": "http://wiki/latency"}),
+		alertmanager.WithTemplateData(struct{ Latency string }{Latency: "1.2s"}),
+	)
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+
+	assert.Equal(t, alertmanager.Labels{
+		"alertname": "HighLatency",
+		"service":   "checkout",
+		"env":       "staging",
+	}, received[0].Labels)
+
+	assert.Equal(t, alertmanager.Annotations{
+		"runbook_url": "http://wiki/latency",
+		"summary":     "HighLatency on checkout",
+		"description": "p99 latency is 1.2s",
+	}, received[0].Annotations)
+
+	assert.Equal(t, "prod", defaults["env"], "default labels must not be modified")
+}
+
+func Test_DefaultLabelsProvideAlertName(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	am, err := alertmanager.NewAlertManager(srv.URL,
+		alertmanager.WithDefaultLabels(alertmanager.Labels{"alertname": "ServiceAlert"}),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, am.Send(alertmanager.WithLabels(alertmanager.Labels{"level": "critical"})))
+}
+
+func Test_InvalidAnnotationTemplate(t *testing.T) {
+	_, err := alertmanager.NewAlertManager("http://localhost:9093",
+		alertmanager.WithAnnotationTemplates(map[string]string{"summary": "{{ .Labels.service "}),
+	)
+	require.ErrorIs(t, err, alertmanager.ErrInvalidTemplate)
+}
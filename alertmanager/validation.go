@@ -0,0 +1,109 @@
+package alertmanager
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// AlertNameLabel is the label every alert must carry.
+	AlertNameLabel = "alertname"
+
+	// MaxLabels is the maximum number of labels on a single alert.
+	MaxLabels = 64
+
+	// MaxLabelNameLength is the maximum length in bytes of a label or annotation name.
+	MaxLabelNameLength = 128
+
+	// MaxLabelValueLength is the maximum length in bytes of a label value.
+	MaxLabelValueLength = 1024
+
+	// MaxAnnotationValueLength is the maximum length in bytes of an annotation value.
+	MaxAnnotationValueLength = 64 * 1024
+)
+
+// labelNamePattern is the label name syntax accepted by Alertmanager.
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// IsValidLabelName reports whether name is accepted by Alertmanager as a label
+// or annotation name.
+func IsValidLabelName(name string) bool {
+	return labelNamePattern.MatchString(name)
+}
+
+// Validate checks that the labels form a valid alert identity:
+//   - at least one label and the alertname label are present
+//   - names match [a-zA-Z_][a-zA-Z0-9_]* and do not use the reserved "__" prefix
+//   - values are valid UTF-8 and the label set stays within the size limits
+func (l Labels) Validate() error {
+	if len(l) == 0 {
+		return ErrMissingLabels
+	}
+
+	if l[AlertNameLabel] == "" {
+		return ErrMissingAlertName
+	}
+
+	if len(l) > MaxLabels {
+		return fmt.Errorf("%w: %d labels, at most %d allowed", ErrLabelLimitExceeded, len(l), MaxLabels)
+	}
+
+	for name, value := range l {
+		if err := validateName(name); err != nil {
+			return err
+		}
+
+		// Labels starting with __ are reserved for internal use by Prometheus and Alertmanager
+		if strings.HasPrefix(name, "__") {
+			return fmt.Errorf("%w: %q", ErrReservedLabel, name)
+		}
+
+		if err := validateValue(name, value, MaxLabelValueLength); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate checks annotation names and values. Annotations are optional,
+// so an empty set is valid.
+func (a Annotations) Validate() error {
+	for name, value := range a {
+		if err := validateName(name); err != nil {
+			return err
+		}
+
+		if err := validateValue(name, value, MaxAnnotationValueLength); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateName(name string) error {
+	if !IsValidLabelName(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidLabelName, name)
+	}
+
+	if len(name) > MaxLabelNameLength {
+		return fmt.Errorf("%w: name %q is longer than %d bytes", ErrLabelLimitExceeded, name, MaxLabelNameLength)
+	}
+
+	return nil
+}
+
+func validateValue(name, value string, maxLength int) error {
+	if !utf8.ValidString(value) {
+		return fmt.Errorf("%w: value of %q is not valid UTF-8", ErrInvalidLabelValue, name)
+	}
+
+	if len(value) > maxLength {
+		return fmt.Errorf("%w: value of %q is longer than %d bytes", ErrLabelLimitExceeded, name, maxLength)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package alertmanager_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LabelsValidate(t *testing.T) {
+	testcases := []struct {
+		name    string
+		labels  alertmanager.Labels
+		wantErr error
+	}{
+		{name: "valid labels", labels: alertmanager.Labels{"alertname": "HighLatency", "service_name": "api"}},
+		{name: "no labels", labels: alertmanager.Labels{}, wantErr: alertmanager.ErrMissingLabels},
+		{name: "missing alertname", labels: alertmanager.Labels{"level": "critical"}, wantErr: alertmanager.ErrMissingAlertName},
+		{
+			name:    "label name with dash",
+			labels:  alertmanager.Labels{"alertname": "HighLatency", "service-name": "api"},
+			wantErr: alertmanager.ErrInvalidLabelName,
+		},
+		{
+			name:    "label name starting with digit",
+			labels:  alertmanager.Labels{"alertname": "HighLatency", "1service": "api"},
+			wantErr: alertmanager.ErrInvalidLabelName,
+		},
+		{
+			name:    "reserved label",
+			labels:  alertmanager.Labels{"alertname": "HighLatency", "__name__": "api"},
+			wantErr: alertmanager.ErrReservedLabel,
+		},
+		{
+			name:    "invalid utf8 value",
+			labels:  alertmanager.Labels{"alertname": "HighLatency", "service": "\xff"},
+			wantErr: alertmanager.ErrInvalidLabelValue,
+		},
+		{
+			name:    "value too long",
+			labels:  alertmanager.Labels{"alertname": strings.Repeat("a", alertmanager.MaxLabelValueLength+1)},
+			wantErr: alertmanager.ErrLabelLimitExceeded,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.labels.Validate()
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func Test_AnnotationsValidate(t *testing.T) {
+	require.NoError(t, alertmanager.Annotations(nil).Validate())
+	require.NoError(t, alertmanager.Annotations{"summary": "p99 above 1s"}.Validate())
+
+	err := alertmanager.Annotations{"runbook-url": "http://wiki"}.Validate()
+	require.ErrorIs(t, err, alertmanager.ErrInvalidLabelName)
+}
+
+func Test_SendRejectsInvalidLabels(t *testing.T) {
+	am, err := alertmanager.NewAlertManager("http://localhost:9093")
+	require.NoError(t, err)
+
+	err = am.Send(alertmanager.WithLabels(alertmanager.Labels{"level": "critical"}))
+	require.ErrorIs(t, err, alertmanager.ErrMissingAlertName)
+}
@@ -0,0 +1,230 @@
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WebhookError is returned when a webhook answers with a non-2xx status.
+type WebhookError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *WebhookError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("webhook %s returned non-2xx status: %s", e.URL, e.Status)
+	}
+
+	return fmt.Sprintf("webhook %s returned non-2xx status: %s: %s", e.URL, e.Status, e.Message)
+}
+
+// WebhookEncoder turns a batch of alerts into the request body of a webhook.
+type WebhookEncoder func(alerts []PostableAlert) ([]byte, error)
+
+// WebhookOption is a functional option type for configuring a WebhookSink.
+type WebhookOption func(*WebhookSink)
+
+// WebhookSink posts alerts as JSON to an HTTP endpoint.
+type WebhookSink struct {
+	url        string
+	httpClient *http.Client
+	headers    http.Header
+	encode     WebhookEncoder
+}
+
+// NewWebhookSink creates a sink that posts every batch of alerts to url.
+// The body uses the Alertmanager webhook format unless another encoder is set.
+//
+// Example:
+//
+//	slack, err := NewWebhookSink("https://hooks.slack.com/services/T000/B000/XXX",
+//	    WithWebhookEncoder(SlackEncoder),
+//	)
+func NewWebhookSink(url string, opts ...WebhookOption) (*WebhookSink, error) {
+	if url == "" {
+		return nil, ErrEmptyHost
+	}
+
+	s := &WebhookSink{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: DefaultClientTimeout,
+		},
+		headers: make(http.Header),
+		encode:  WebhookJSONEncoder,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// WithWebhookHTTPClient returns a WebhookOption that replaces the default HTTP client.
+// A nil client is ignored.
+func WithWebhookHTTPClient(client *http.Client) WebhookOption {
+	return func(s *WebhookSink) {
+		if client == nil {
+			return
+		}
+		s.httpClient = client
+	}
+}
+
+// WithWebhookHeaders returns a WebhookOption that adds headers to every request,
+// for example an Authorization header.
+func WithWebhookHeaders(headers map[string]string) WebhookOption {
+	return func(s *WebhookSink) {
+		for key, value := range headers {
+			s.headers.Set(key, value)
+		}
+	}
+}
+
+// WithWebhookEncoder returns a WebhookOption that changes the request body format.
+// A nil encoder is ignored.
+func WithWebhookEncoder(encode WebhookEncoder) WebhookOption {
+	return func(s *WebhookSink) {
+		if encode == nil {
+			return
+		}
+		s.encode = encode
+	}
+}
+
+// Notify posts the alerts to the webhook.
+func (s *WebhookSink) Notify(ctx context.Context, alerts []PostableAlert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	body, err := s.encode(alerts)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range s.headers {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &WebhookError{
+			URL:        s.url,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Message:    strings.TrimSpace(string(msg)),
+		}
+	}
+
+	return nil
+}
+
+// WebhookMessage is the body written by WebhookJSONEncoder. It follows the
+// Alertmanager webhook format so existing receivers can consume it.
+type WebhookMessage struct {
+	Version string         `json:"version"`
+	Status  string         `json:"status"`
+	Alerts  []WebhookAlert `json:"alerts"`
+}
+
+// WebhookAlert is a single alert of a WebhookMessage.
+type WebhookAlert struct {
+	Status       string      `json:"status"`
+	Labels       Labels      `json:"labels"`
+	Annotations  Annotations `json:"annotations"`
+	StartsAt     time.Time   `json:"startsAt"`
+	EndsAt       time.Time   `json:"endsAt"`
+	GeneratorURL string      `json:"generatorURL"`
+	Fingerprint  string      `json:"fingerprint"`
+}
+
+// WebhookJSONEncoder encodes the alerts in the Alertmanager webhook format.
+// The message status is firing as long as one of the alerts is firing.
+func WebhookJSONEncoder(alerts []PostableAlert) ([]byte, error) {
+	msg := WebhookMessage{
+		Version: "4",
+		Status:  "resolved",
+		Alerts:  make([]WebhookAlert, 0, len(alerts)),
+	}
+
+	for _, a := range alerts {
+		status := a.Status()
+		if status == "firing" {
+			msg.Status = status
+		}
+
+		msg.Alerts = append(msg.Alerts, WebhookAlert{
+			Status:       status,
+			Labels:       a.Labels,
+			Annotations:  a.Annotations,
+			StartsAt:     a.StartsAt,
+			EndsAt:       a.EndsAt,
+			GeneratorURL: a.GeneratorURL,
+			Fingerprint:  a.Labels.Fingerprint(),
+		})
+	}
+
+	return json.Marshal(msg)
+}
+
+// SlackEncoder encodes the alerts as a Slack incoming webhook message.
+// Mattermost, Rocket.Chat and most chat tools accept the same format.
+// Each alert takes one line with its status, name, summary and labels:
+//
+//	[FIRING] HighLatency: p99 above 2s (service=checkout, severity=critical)
+func SlackEncoder(alerts []PostableAlert) ([]byte, error) {
+	lines := make([]string, 0, len(alerts))
+
+	for _, a := range alerts {
+		line := fmt.Sprintf("[%s] %s", strings.ToUpper(a.Status()), a.Labels[AlertNameLabel])
+
+		if summary := a.Annotations["summary"]; summary != "" {
+			line += ": " + summary
+		}
+
+		pairs := make([]string, 0, len(a.Labels))
+		for _, name := range slices.Sorted(maps.Keys(a.Labels)) {
+			if name == AlertNameLabel {
+				continue
+			}
+			pairs = append(pairs, name+"="+a.Labels[name])
+		}
+		if len(pairs) > 0 {
+			line += " (" + strings.Join(pairs, ", ") + ")"
+		}
+
+		if a.GeneratorURL != "" {
+			line += " <" + a.GeneratorURL + ">"
+		}
+
+		lines = append(lines, line)
+	}
+
+	return json.Marshal(map[string]string{"text": strings.Join(lines, "\n")})
+}
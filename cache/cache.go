@@ -2,19 +2,45 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
 	"time"
 )
 
+// Cache is implemented by every backend with the same semantics, which the
+// cachetest conformance suite checks.
 type Cache interface {
 	// Get retrieves a value from the cache by its key.
+	// A missing or expired key returns an error wrapping ErrKeyNotFound.
 	Get(ctx context.Context, key string) (string, error)
 
 	// Set stores a value in the cache with an optional expiration time.
+	// A zero expiration keeps the value until it is deleted or evicted.
+	// Empty values are stored and read back as empty strings.
 	Set(ctx context.Context, key string, value any, expiration time.Duration) error
 
-	// Del removes one or more keys from the cache.
+	// Del removes one or more keys from the cache. Missing keys are ignored.
 	Del(ctx context.Context, keys ...string) error
 
+	// MGet retrieves several keys at once. The results are in the order of
+	// keys, the error of a missing key wraps ErrKeyNotFound. The returned
+	// error is only set when the whole batch failed.
+	MGet(ctx context.Context, keys ...string) ([]Result, error)
+
+	// MSet stores several values at once, each with its own expiration.
+	MSet(ctx context.Context, items ...Item) error
+
+	// Exists reports for each key, in order, whether it is in the cache.
+	Exists(ctx context.Context, keys ...string) ([]bool, error)
+
+	// SetWithTags stores a value like Set and attaches tags to the key, so
+	// InvalidateTag can remove it together with the other keys of a tag.
+	SetWithTags(ctx context.Context, key string, value any, expiration time.Duration, tags ...string) error
+
+	// InvalidateTag removes every key attached to one of the tags. A key
+	// stays attached until the tag is invalidated, even when it is later
+	// overwritten by Set. Unknown tags are ignored.
+	InvalidateTag(ctx context.Context, tags ...string) error
+
 	// Ping checks the connection to the cache server.
 	Ping(ctx context.Context) error
 
@@ -22,25 +48,95 @@ type Cache interface {
 	Close() error
 }
 
+// Item is a value stored with MSet. A zero TTL keeps the value until it is
+// deleted or evicted.
+type Item struct {
+	Key   string
+	Value any
+	TTL   time.Duration
+}
+
+// Result is the outcome of a single key of MGet.
+type Result struct {
+	Key   string
+	Value string
+
+	// Err wraps ErrKeyNotFound when the key is missing.
+	Err error
+}
+
+// Found reports whether the key was in the cache.
+func (r Result) Found() bool {
+	return r.Err == nil
+}
+
+// Config holds the settings of the cache returned by NewCache.
+//
+// Redis runs in one of three topologies:
+//   - a single node at Host and Port
+//   - Sentinel when MasterName is set, Addrs lists the Sentinels
+//   - Cluster when IsCluster is set, Addrs lists the seed nodes
 type Config struct {
 	IsCacheOnMemory bool
 
+	// DSN configures Redis with a URL, see ParseDSN. It fills the fields
+	// left empty, so explicit fields win over the DSN.
+	DSN string
+
 	Host     string
 	Port     int
+	Username string
 	Password string
 	DB       int
+
+	// Addrs lists the Sentinel or Cluster nodes as host:port.
+	Addrs []string
+
+	// MasterName is the Sentinel master to connect to.
+	MasterName       string
+	SentinelUsername string
+	SentinelPassword string
+
+	IsCluster bool
+
+	// TLSConfig enables TLS when set.
+	TLSConfig *tls.Config
+
+	// Connection pool and timeouts, zero values fall back to:
+	//   - PoolSize: 10
+	//   - MinIdleConns: 5
+	//   - DialTimeout: 5s
+	//   - ReadTimeout: 3s
+	//   - WriteTimeout: 3s
+	// and to the go-redis defaults for the others.
+	PoolSize        int
+	MinIdleConns    int
+	MaxIdleConns    int
+	PoolTimeout     time.Duration
+	ConnMaxIdleTime time.Duration
+	ConnMaxLifetime time.Duration
+	DialTimeout     time.Duration
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+
+	// Tiered puts a local in-memory cache in front of Redis when set,
+	// see NewTieredCache.
+	Tiered *TieredConfig
 }
 
 func NewCache(config Config) (Cache, error) {
+	if config.IsCacheOnMemory {
+		return NewRistrettoCache()
+	}
+
 	// Validate configuration
-	if !config.IsCacheOnMemory {
-		if config.Host == "" {
-			return nil, ErrMissingHost
-		}
+	config, err := config.resolve()
+	if err != nil {
+		return nil, err
 	}
 
-	if config.IsCacheOnMemory {
-		return NewRistrettoCache()
+	if config.Tiered != nil {
+		return NewTieredCache(config)
 	}
 
 	return NewRedisCache(config)
@@ -0,0 +1,237 @@
+// Package cachetest provides a conformance suite that every cache.Cache
+// implementation runs to prove it behaves like the others.
+//
+// Example:
+//
+//	func Test_MyCacheConformance(t *testing.T) {
+//	    cachetest.RunConformance(t, func(t *testing.T) cache.Cache {
+//	        return newMyCache(t)
+//	    })
+//	}
+package cachetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns an empty cache for a single test. It should register the
+// cleanup of the cache with t.Cleanup.
+type Factory func(t *testing.T) cache.Cache
+
+// RunConformance runs the conformance suite as subtests of t. Every subtest
+// gets its own cache from newCache and uses keys unique to the test run, so
+// the suite can also run against a shared Redis.
+func RunConformance(t *testing.T, newCache Factory) {
+	t.Helper()
+
+	prefix := fmt.Sprintf("cachetest:%d:", time.Now().UnixNano())
+	key := func(t *testing.T, name string) string {
+		return prefix + t.Name() + ":" + name
+	}
+
+	t.Run("missing key", func(t *testing.T) {
+		c := newCache(t)
+
+		_, err := c.Get(context.Background(), key(t, "missing"))
+		require.ErrorIs(t, err, cache.ErrKeyNotFound)
+	})
+
+	t.Run("set and get", func(t *testing.T) {
+		c := newCache(t)
+		ctx := context.Background()
+
+		testcases := []struct {
+			name     string
+			value    any
+			expected string
+		}{
+			{name: "string", value: "hello", expected: "hello"},
+			{name: "bytes", value: []byte("raw"), expected: "raw"},
+			{name: "empty string", value: "", expected: ""},
+			{name: "number", value: 42, expected: "42"},
+			{name: "struct", value: struct {
+				Name string `json:"name"`
+			}{Name: "Ada"}, expected: `{"name":"Ada"}`},
+		}
+
+		for _, tc := range testcases {
+			k := key(t, tc.name)
+			require.NoError(t, c.Set(ctx, k, tc.value, time.Minute), tc.name)
+
+			actual, err := c.Get(ctx, k)
+			require.NoError(t, err, tc.name)
+			assert.Equal(t, tc.expected, actual, tc.name)
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		c := newCache(t)
+		ctx := context.Background()
+		k := key(t, "value")
+
+		require.NoError(t, c.Set(ctx, k, "first", time.Minute))
+		require.NoError(t, c.Set(ctx, k, "second", time.Minute))
+
+		actual, err := c.Get(ctx, k)
+		require.NoError(t, err)
+		assert.Equal(t, "second", actual)
+	})
+
+	t.Run("ttl expires", func(t *testing.T) {
+		c := newCache(t)
+		ctx := context.Background()
+		k := key(t, "short")
+
+		require.NoError(t, c.Set(ctx, k, "value", 100*time.Millisecond))
+
+		_, err := c.Get(ctx, k)
+		require.NoError(t, err, "value must be readable before it expires")
+
+		require.Eventually(t, func() bool {
+			_, err := c.Get(ctx, k)
+			return err != nil
+		}, 2*time.Second, 20*time.Millisecond)
+
+		_, err = c.Get(ctx, k)
+		require.ErrorIs(t, err, cache.ErrKeyNotFound)
+	})
+
+	t.Run("zero ttl does not expire", func(t *testing.T) {
+		c := newCache(t)
+		ctx := context.Background()
+		k := key(t, "forever")
+
+		require.NoError(t, c.Set(ctx, k, "value", 0))
+		t.Cleanup(func() { c.Del(context.Background(), k) })
+
+		time.Sleep(50 * time.Millisecond)
+
+		actual, err := c.Get(ctx, k)
+		require.NoError(t, err)
+		assert.Equal(t, "value", actual)
+	})
+
+	t.Run("del", func(t *testing.T) {
+		c := newCache(t)
+		ctx := context.Background()
+		a, b, kept := key(t, "a"), key(t, "b"), key(t, "kept")
+
+		for _, k := range []string{a, b, kept} {
+			require.NoError(t, c.Set(ctx, k, "value", time.Minute))
+		}
+
+		require.NoError(t, c.Del(ctx, a, b, key(t, "missing")))
+
+		for _, k := range []string{a, b} {
+			_, err := c.Get(ctx, k)
+			require.ErrorIs(t, err, cache.ErrKeyNotFound)
+		}
+
+		actual, err := c.Get(ctx, kept)
+		require.NoError(t, err)
+		assert.Equal(t, "value", actual)
+
+		require.NoError(t, c.Del(ctx), "del without keys is a no-op")
+	})
+
+	t.Run("mset and mget", func(t *testing.T) {
+		c := newCache(t)
+		ctx := context.Background()
+		a, b, short, missing := key(t, "a"), key(t, "b"), key(t, "short"), key(t, "missing")
+
+		require.NoError(t, c.MSet(ctx,
+			cache.Item{Key: a, Value: "1", TTL: time.Minute},
+			cache.Item{Key: b, Value: "", TTL: 0},
+			cache.Item{Key: short, Value: "3", TTL: 100 * time.Millisecond},
+		))
+		t.Cleanup(func() { c.Del(context.Background(), b) })
+
+		results, err := c.MGet(ctx, a, missing, b, short)
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+
+		assert.Equal(t, cache.Result{Key: a, Value: "1"}, results[0])
+		assert.Equal(t, missing, results[1].Key)
+		assert.False(t, results[1].Found())
+		require.ErrorIs(t, results[1].Err, cache.ErrKeyNotFound)
+		assert.Equal(t, cache.Result{Key: b, Value: ""}, results[2], "empty values are found")
+		assert.Equal(t, cache.Result{Key: short, Value: "3"}, results[3])
+
+		require.Eventually(t, func() bool {
+			results, err := c.MGet(ctx, short)
+			return err == nil && !results[0].Found()
+		}, 2*time.Second, 20*time.Millisecond, "each item keeps its own ttl")
+
+		results, err = c.MGet(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		require.NoError(t, c.MSet(ctx), "mset without items is a no-op")
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		c := newCache(t)
+		ctx := context.Background()
+		present, empty, missing := key(t, "present"), key(t, "empty"), key(t, "missing")
+
+		require.NoError(t, c.Set(ctx, present, "value", time.Minute))
+		require.NoError(t, c.Set(ctx, empty, "", time.Minute))
+
+		exists, err := c.Exists(ctx, present, missing, empty)
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true, false, true}, exists)
+
+		exists, err = c.Exists(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, exists)
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		c := newCache(t)
+		ctx := context.Background()
+		product, reviews, listing, other := key(t, "product"), key(t, "reviews"), key(t, "listing"), key(t, "other")
+		productTag, listingTag := key(t, "tag:product"), key(t, "tag:listing")
+
+		require.NoError(t, c.SetWithTags(ctx, product, "1", time.Minute, productTag))
+		require.NoError(t, c.SetWithTags(ctx, reviews, "2", 0, productTag))
+		require.NoError(t, c.SetWithTags(ctx, listing, "3", time.Minute, productTag, listingTag))
+		require.NoError(t, c.Set(ctx, other, "4", time.Minute))
+		t.Cleanup(func() { c.Del(context.Background(), reviews) })
+
+		actual, err := c.Get(ctx, listing)
+		require.NoError(t, err)
+		assert.Equal(t, "3", actual, "tagged values are readable")
+
+		require.NoError(t, c.InvalidateTag(ctx, listingTag))
+
+		exists, err := c.Exists(ctx, product, reviews, listing, other)
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true, true, false, true}, exists)
+
+		require.NoError(t, c.InvalidateTag(ctx, productTag, key(t, "tag:missing")))
+
+		exists, err = c.Exists(ctx, product, reviews, listing, other)
+		require.NoError(t, err)
+		assert.Equal(t, []bool{false, false, false, true}, exists)
+
+		// An invalidated tag starts empty
+		require.NoError(t, c.SetWithTags(ctx, product, "5", time.Minute, productTag))
+		require.NoError(t, c.InvalidateTag(ctx, productTag))
+		_, err = c.Get(ctx, product)
+		require.ErrorIs(t, err, cache.ErrKeyNotFound)
+
+		require.NoError(t, c.InvalidateTag(ctx), "invalidate without tags is a no-op")
+	})
+
+	t.Run("ping", func(t *testing.T) {
+		c := newCache(t)
+
+		require.NoError(t, c.Ping(context.Background()))
+	})
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ugorji/go/codec"
+)
+
+// Codec turns values into bytes stored in the cache and back.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSONCodec encodes values as JSON. It is the default codec of TypedCache.
+	JSONCodec Codec = jsonCodec{}
+
+	// GobCodec encodes values with encoding/gob, which keeps Go types such as
+	// time.Time and maps with non-string keys without extra tags.
+	GobCodec Codec = gobCodec{}
+
+	// MsgpackCodec encodes values as MessagePack, a compact binary alternative
+	// to JSON that reads the same json struct tags.
+	MsgpackCodec Codec = msgpackCodec{}
+
+	// BinaryCodec delegates to the encoding.BinaryMarshaler and
+	// encoding.BinaryUnmarshaler methods of the value, which is how protobuf
+	// and other generated types are usually wired.
+	BinaryCodec Codec = binaryCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// msgpackHandle is safe for concurrent use once configured.
+var msgpackHandle = func() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{}
+	h.WriteExt = true
+	h.TypeInfos = codec.NewTypeInfos([]string{"json"})
+	return h
+}()
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	var b []byte
+	if err := codec.NewEncoderBytes(&b, msgpackHandle).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	return codec.NewDecoderBytes(data, msgpackHandle).Decode(v)
+}
+
+type binaryCodec struct{}
+
+func (binaryCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T does not implement encoding.BinaryMarshaler", ErrUnsupportedValue, v)
+	}
+
+	return m.MarshalBinary()
+}
+
+func (binaryCodec) Unmarshal(data []byte, v any) error {
+	u, ok := v.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return fmt.Errorf("%w: %T does not implement encoding.BinaryUnmarshaler", ErrUnsupportedValue, v)
+	}
+
+	return u.UnmarshalBinary(data)
+}
+
+// encodeValue converts a value passed to Cache.Set into the stored string.
+// Every backend uses it so a value reads back the same whatever the backend:
+// strings and bytes are stored as is, BinaryMarshaler values with their own
+// encoding and anything else as JSON.
+func encodeValue(value any) (string, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case encoding.BinaryMarshaler:
+		b, err = v.MarshalBinary()
+	default:
+		b, err = json.Marshal(v)
+	}
+	if err != nil {
+		return "", fmt.Errorf("serialize cache value: %w", err)
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,76 @@
+package cache_test
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/cache"
+	"github.com/DucTran999/shared-pkg/cache/cachetest"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRedis starts an in-process Redis. Its clock follows the real one so
+// TTLs expire like on a real server.
+func newTestRedis(t *testing.T) *miniredis.Miniredis {
+	mr := miniredis.RunT(t)
+
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				mr.FastForward(10 * time.Millisecond)
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return mr
+}
+
+// redisConfig returns the cache config that connects to mr.
+func redisConfig(t *testing.T, mr *miniredis.Miniredis) cache.Config {
+	host, portStr, err := net.SplitHostPort(mr.Addr())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+
+	return cache.Config{Host: host, Port: port}
+}
+
+func newTestRedisCache(t *testing.T, mr *miniredis.Miniredis) cache.Cache {
+	c, err := cache.NewRedisCache(redisConfig(t, mr))
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+
+	return c
+}
+
+func Test_RistrettoConformance(t *testing.T) {
+	cachetest.RunConformance(t, newTestCache)
+}
+
+func Test_RedisConformance(t *testing.T) {
+	mr := newTestRedis(t)
+
+	cachetest.RunConformance(t, func(t *testing.T) cache.Cache {
+		return newTestRedisCache(t, mr)
+	})
+}
+
+func Test_TieredConformance(t *testing.T) {
+	mr := newTestRedis(t)
+
+	cachetest.RunConformance(t, func(t *testing.T) cache.Cache {
+		return newTestTieredCache(t, mr, time.Minute)
+	})
+}
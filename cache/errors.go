@@ -4,5 +4,13 @@ import "errors"
 
 var (
 	ErrKeyNotFound = errors.New("key not found in cache")
+	ErrNotStored   = errors.New("value was not stored in cache")
 	ErrMissingHost = errors.New("missing host")
+	ErrInvalidDSN  = errors.New("invalid redis dsn")
+
+	ErrUnsupportedValue = errors.New("unsupported cache value")
+
+	ErrLockNotAcquired = errors.New("lock is held by another owner")
+	ErrLockNotHeld     = errors.New("lock is not held")
+	ErrLockUnsupported = errors.New("cache does not support locks")
 )
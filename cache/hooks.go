@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"context"
+	"strings"
+)
+
+// Counter is a Prometheus-style counter vector, labels are passed in the
+// order documented by the metric.
+type Counter interface {
+	Add(value float64, labels ...string)
+}
+
+// Histogram is a Prometheus-style histogram vector.
+type Histogram interface {
+	Observe(value float64, labels ...string)
+}
+
+// Metrics holds the metrics reported by NewMetricsHook, nil ones are skipped.
+//
+// Hits, Misses, Sets, Errors and Latency take the op and prefix labels, in
+// this order. Latency is in seconds and observed once per operation, with an
+// empty prefix when the operation spans several prefixes or has no key.
+// Evictions has no label and only counts the keys evicted to make room, not
+// the expired ones.
+type Metrics struct {
+	Hits      Counter
+	Misses    Counter
+	Sets      Counter
+	Errors    Counter
+	Evictions Counter
+	Latency   Histogram
+}
+
+type metricsHook struct {
+	metrics Metrics
+}
+
+// NewMetricsHook returns a Hook that updates the metrics after every operation.
+//
+// The interfaces keep this package free of a metrics library. With Prometheus,
+// a small adapter does:
+//
+//	type counter struct{ *prometheus.CounterVec }
+//
+//	func (c counter) Add(v float64, labels ...string) {
+//	    c.WithLabelValues(labels...).Add(v)
+//	}
+//
+//	hook := cache.NewMetricsHook(cache.Metrics{
+//	    Hits: counter{prometheus.NewCounterVec(prometheus.CounterOpts{
+//	        Name: "cache_hits_total",
+//	    }, []string{"op", "prefix"})},
+//	})
+func NewMetricsHook(metrics Metrics) Hook {
+	return &metricsHook{metrics: metrics}
+}
+
+func (h *metricsHook) Start(ctx context.Context, op Op) context.Context {
+	return ctx
+}
+
+func (h *metricsHook) Finish(ctx context.Context, event Event) {
+	op := string(event.Op)
+
+	// Operations without keys, such as Ping, still report their latency
+	prefixes := event.Prefixes
+	if len(prefixes) == 0 {
+		prefixes = []PrefixEvent{{Errors: boolToInt(event.Err != nil)}}
+	}
+
+	for _, pe := range prefixes {
+		add(h.metrics.Hits, pe.Hits, op, pe.Prefix)
+		add(h.metrics.Misses, pe.Misses, op, pe.Prefix)
+		add(h.metrics.Sets, pe.Sets, op, pe.Prefix)
+		add(h.metrics.Errors, pe.Errors, op, pe.Prefix)
+	}
+
+	if h.metrics.Latency != nil {
+		// One observation per operation, so multi-prefix calls are not weighted more
+		var prefix string
+		if len(prefixes) == 1 {
+			prefix = prefixes[0].Prefix
+		}
+
+		h.metrics.Latency.Observe(event.Duration.Seconds(), op, prefix)
+	}
+}
+
+func (h *metricsHook) Evicted() {
+	if h.metrics.Evictions != nil {
+		h.metrics.Evictions.Add(1)
+	}
+}
+
+// add skips zero values, so counters are only created for what happens.
+func add(counter Counter, value int, labels ...string) {
+	if counter != nil && value > 0 {
+		counter.Add(float64(value), labels...)
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// Tracer starts spans, for example an adapter over an OpenTelemetry tracer.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is the part of a tracing span used by the tracing hook.
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+type tracingHook struct {
+	tracer Tracer
+}
+
+type spanKey struct{}
+
+// NewTracingHook returns a Hook that wraps every operation in a span named
+// after it, such as cache.get, with the cache.operation, cache.prefixes,
+// cache.keys, cache.hits and cache.misses attributes. Misses are not errors.
+//
+// With OpenTelemetry, the adapter converts the attributes:
+//
+//	type tracer struct{ trace.Tracer }
+//
+//	func (t tracer) Start(ctx context.Context, name string) (context.Context, cache.Span) {
+//	    ctx, span := t.Tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//	    return ctx, otelSpan{span}
+//	}
+func NewTracingHook(tracer Tracer) Hook {
+	return &tracingHook{tracer: tracer}
+}
+
+func (h *tracingHook) Start(ctx context.Context, op Op) context.Context {
+	ctx, span := h.tracer.Start(ctx, "cache."+string(op))
+
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+func (h *tracingHook) Finish(ctx context.Context, event Event) {
+	span, ok := ctx.Value(spanKey{}).(Span)
+	if !ok {
+		return
+	}
+
+	var prefixes []string
+	var keys, hits, misses int
+	for _, pe := range event.Prefixes {
+		prefixes = append(prefixes, pe.Prefix)
+		keys += pe.Keys
+		hits += pe.Hits
+		misses += pe.Misses
+	}
+
+	span.SetAttribute("cache.operation", string(event.Op))
+	span.SetAttribute("cache.prefixes", strings.Join(prefixes, ","))
+	span.SetAttribute("cache.keys", keys)
+	span.SetAttribute("cache.hits", hits)
+	span.SetAttribute("cache.misses", misses)
+
+	if event.Err != nil {
+		span.RecordError(event.Err)
+	}
+
+	span.End()
+}
@@ -0,0 +1,498 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Op names a cache operation in Event.
+type Op string
+
+const (
+	OpGet           Op = "get"
+	OpSet           Op = "set"
+	OpDel           Op = "del"
+	OpMGet          Op = "mget"
+	OpMSet          Op = "mset"
+	OpExists        Op = "exists"
+	OpSetWithTags   Op = "set_with_tags"
+	OpInvalidateTag Op = "invalidate_tag"
+	OpPing          Op = "ping"
+)
+
+// Event describes a finished cache operation.
+type Event struct {
+	Op       Op
+	Duration time.Duration
+
+	// Err is the error returned by the operation. A missing key is a miss,
+	// not an error.
+	Err error
+
+	// Prefixes breaks the keys of the operation down by key prefix. The
+	// prefixes of InvalidateTag come from its tags.
+	Prefixes []PrefixEvent
+}
+
+// PrefixEvent counts the keys of an operation that share a prefix.
+type PrefixEvent struct {
+	Prefix string
+	Keys   int
+	Hits   int
+	Misses int
+	Sets   int
+
+	// Errors counts the failed keys, or every key when the operation failed.
+	Errors int
+}
+
+// Hook observes the operations of an InstrumentedCache. Hooks are called
+// concurrently and must not block.
+type Hook interface {
+	// Start is called before an operation. The returned context is passed
+	// to the cache and to Finish, so a tracing hook can carry a span in it.
+	Start(ctx context.Context, op Op) context.Context
+
+	// Finish is called once the operation returned.
+	Finish(ctx context.Context, event Event)
+}
+
+// EvictionHook is implemented by hooks that want to know when the in-memory
+// tier evicts a key to make room. Keys removed because they expired are not
+// evictions. Redis evicts on the server, where the evictions show in INFO
+// stats instead.
+type EvictionHook interface {
+	Evicted()
+}
+
+// evictionNotifier is implemented by the backends that evict keys on their own.
+type evictionNotifier interface {
+	onEvict(fn func())
+}
+
+// InstrumentOption is a functional option type for configuring NewInstrumentedCache.
+type InstrumentOption func(*InstrumentedCache)
+
+// WithHooks returns an InstrumentOption that reports every operation to the
+// hooks, in order.
+func WithHooks(hooks ...Hook) InstrumentOption {
+	return func(c *InstrumentedCache) {
+		c.hooks = append(c.hooks, hooks...)
+	}
+}
+
+// WithKeyPrefix returns an InstrumentOption that groups the keys by the
+// prefix fn returns instead of DefaultKeyPrefix. The prefixes end up in
+// metric labels, so fn must return a small set of values.
+func WithKeyPrefix(fn func(key string) string) InstrumentOption {
+	return func(c *InstrumentedCache) {
+		if fn != nil {
+			c.prefix = fn
+		}
+	}
+}
+
+// DefaultKeyPrefix returns the part of key before the first colon, so
+// product:1 and product:1:reviews both report under product. Keys without
+// a colon have an empty prefix.
+func DefaultKeyPrefix(key string) string {
+	prefix, _, ok := strings.Cut(key, ":")
+	if !ok {
+		return ""
+	}
+
+	return prefix
+}
+
+// InstrumentedCache wraps a Cache to count hits, misses, errors, sets and
+// evictions and to time the operations, per key prefix. The counts are read
+// with Stats and reported to the hooks as the operations finish.
+type InstrumentedCache struct {
+	next   Cache
+	hooks  []Hook
+	prefix func(key string) string
+
+	errors    atomic.Uint64
+	evictions atomic.Uint64
+
+	mu       sync.RWMutex
+	prefixes map[string]*prefixCounters
+}
+
+type prefixCounters struct {
+	operations atomic.Uint64
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+	errors     atomic.Uint64
+	sets       atomic.Uint64
+	latency    atomic.Int64
+}
+
+// NewInstrumentedCache wraps c. The wrapper works with NewLocker,
+// RedisClient and the other helpers taking a Cache like c itself.
+//
+// Example:
+//
+//	instrumented := cache.NewInstrumentedCache(c,
+//	    cache.WithHooks(cache.NewMetricsHook(metrics), cache.NewTracingHook(tracer)),
+//	)
+//	users := cache.NewTypedCache[User](instrumented)
+//
+//	stats := instrumented.Stats()
+//	log.Info().Float64("hit_ratio", stats.HitRatio()).Msg("cache stats")
+func NewInstrumentedCache(c Cache, opts ...InstrumentOption) *InstrumentedCache {
+	ic := &InstrumentedCache{
+		next:     c,
+		prefix:   DefaultKeyPrefix,
+		prefixes: make(map[string]*prefixCounters),
+	}
+
+	for _, opt := range opts {
+		opt(ic)
+	}
+
+	if notifier, ok := unwrap(c).(evictionNotifier); ok {
+		notifier.onEvict(ic.evicted)
+	}
+
+	return ic
+}
+
+// Unwrap returns the wrapped cache.
+func (c *InstrumentedCache) Unwrap() Cache {
+	return c.next
+}
+
+func (c *InstrumentedCache) Get(ctx context.Context, key string) (string, error) {
+	ctx, op := c.start(ctx, OpGet, key)
+
+	val, err := c.next.Get(ctx, key)
+	switch {
+	case err == nil:
+		op.hit(key)
+		op.finish(nil)
+	case errors.Is(err, ErrKeyNotFound):
+		op.miss(key)
+		op.finish(nil)
+	default:
+		op.finish(err)
+	}
+
+	return val, err
+}
+
+func (c *InstrumentedCache) Set(ctx context.Context, key string, value any, expiration time.Duration) error {
+	ctx, op := c.start(ctx, OpSet, key)
+
+	err := c.next.Set(ctx, key, value, expiration)
+	if err == nil {
+		op.set(key)
+	}
+	op.finish(err)
+
+	return err
+}
+
+func (c *InstrumentedCache) Del(ctx context.Context, keys ...string) error {
+	ctx, op := c.start(ctx, OpDel, keys...)
+
+	err := c.next.Del(ctx, keys...)
+	op.finish(err)
+
+	return err
+}
+
+func (c *InstrumentedCache) MGet(ctx context.Context, keys ...string) ([]Result, error) {
+	ctx, op := c.start(ctx, OpMGet, keys...)
+
+	results, err := c.next.MGet(ctx, keys...)
+	for _, res := range results {
+		switch {
+		case res.Found():
+			op.hit(res.Key)
+		case errors.Is(res.Err, ErrKeyNotFound):
+			op.miss(res.Key)
+		default:
+			op.fail(res.Key)
+		}
+	}
+	op.finish(err)
+
+	return results, err
+}
+
+func (c *InstrumentedCache) MSet(ctx context.Context, items ...Item) error {
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = item.Key
+	}
+
+	ctx, op := c.start(ctx, OpMSet, keys...)
+
+	err := c.next.MSet(ctx, items...)
+	if err == nil {
+		for _, key := range keys {
+			op.set(key)
+		}
+	}
+	op.finish(err)
+
+	return err
+}
+
+func (c *InstrumentedCache) Exists(ctx context.Context, keys ...string) ([]bool, error) {
+	ctx, op := c.start(ctx, OpExists, keys...)
+
+	exists, err := c.next.Exists(ctx, keys...)
+	op.finish(err)
+
+	return exists, err
+}
+
+func (c *InstrumentedCache) SetWithTags(ctx context.Context, key string, value any, expiration time.Duration, tags ...string) error {
+	ctx, op := c.start(ctx, OpSetWithTags, key)
+
+	err := c.next.SetWithTags(ctx, key, value, expiration, tags...)
+	if err == nil {
+		op.set(key)
+	}
+	op.finish(err)
+
+	return err
+}
+
+func (c *InstrumentedCache) InvalidateTag(ctx context.Context, tags ...string) error {
+	ctx, op := c.start(ctx, OpInvalidateTag, tags...)
+
+	err := c.next.InvalidateTag(ctx, tags...)
+	op.finish(err)
+
+	return err
+}
+
+func (c *InstrumentedCache) Ping(ctx context.Context) error {
+	ctx, op := c.start(ctx, OpPing)
+
+	err := c.next.Ping(ctx)
+	op.finish(err)
+
+	return err
+}
+
+func (c *InstrumentedCache) Close() error {
+	return c.next.Close()
+}
+
+// Stats is a snapshot of the counters of an InstrumentedCache.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Sets      uint64
+	Evictions uint64
+
+	// Errors counts the failed operations and the failed keys of MGet.
+	Errors uint64
+
+	Prefixes map[string]PrefixStats
+}
+
+// HitRatio returns the share of reads that found their key, or 0 without reads.
+func (s Stats) HitRatio() float64 {
+	return hitRatio(s.Hits, s.Misses)
+}
+
+// PrefixStats holds the counters of the keys sharing a prefix.
+type PrefixStats struct {
+	Operations uint64
+	Hits       uint64
+	Misses     uint64
+	Errors     uint64
+	Sets       uint64
+
+	// Latency is the total time spent in the operations.
+	Latency time.Duration
+}
+
+// HitRatio returns the share of reads that found their key, or 0 without reads.
+func (s PrefixStats) HitRatio() float64 {
+	return hitRatio(s.Hits, s.Misses)
+}
+
+// MeanLatency returns the average duration of an operation.
+func (s PrefixStats) MeanLatency() time.Duration {
+	if s.Operations == 0 {
+		return 0
+	}
+
+	return s.Latency / time.Duration(s.Operations)
+}
+
+func hitRatio(hits, misses uint64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+
+	return float64(hits) / float64(hits+misses)
+}
+
+// Stats returns the counters since the cache was wrapped.
+func (c *InstrumentedCache) Stats() Stats {
+	stats := Stats{
+		Errors:    c.errors.Load(),
+		Evictions: c.evictions.Load(),
+		Prefixes:  make(map[string]PrefixStats),
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for prefix, counters := range c.prefixes {
+		ps := PrefixStats{
+			Operations: counters.operations.Load(),
+			Hits:       counters.hits.Load(),
+			Misses:     counters.misses.Load(),
+			Errors:     counters.errors.Load(),
+			Sets:       counters.sets.Load(),
+			Latency:    time.Duration(counters.latency.Load()),
+		}
+
+		stats.Hits += ps.Hits
+		stats.Misses += ps.Misses
+		stats.Sets += ps.Sets
+		stats.Prefixes[prefix] = ps
+	}
+
+	return stats
+}
+
+func (c *InstrumentedCache) evicted() {
+	c.evictions.Add(1)
+
+	for _, hook := range c.hooks {
+		if h, ok := hook.(EvictionHook); ok {
+			h.Evicted()
+		}
+	}
+}
+
+// counters returns the counters of prefix, creating them on first use.
+func (c *InstrumentedCache) counters(prefix string) *prefixCounters {
+	c.mu.RLock()
+	counters, ok := c.prefixes[prefix]
+	c.mu.RUnlock()
+
+	if ok {
+		return counters
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if counters, ok = c.prefixes[prefix]; !ok {
+		counters = &prefixCounters{}
+		c.prefixes[prefix] = counters
+	}
+
+	return counters
+}
+
+// operation tracks a single call between start and finish.
+type operation struct {
+	cache *InstrumentedCache
+	ctxs  []context.Context
+	begin time.Time
+	event Event
+	index map[string]int
+}
+
+// start groups the keys by prefix and calls the hooks.
+func (c *InstrumentedCache) start(ctx context.Context, op Op, keys ...string) (context.Context, *operation) {
+	o := &operation{
+		cache: c,
+		event: Event{Op: op},
+		index: make(map[string]int),
+	}
+
+	for _, key := range keys {
+		o.prefix(key).Keys++
+	}
+
+	// Each hook finishes with the context it returned
+	o.ctxs = make([]context.Context, len(c.hooks))
+	for i, hook := range c.hooks {
+		ctx = hook.Start(ctx, op)
+		o.ctxs[i] = ctx
+	}
+
+	o.begin = time.Now()
+
+	return ctx, o
+}
+
+// prefix returns the PrefixEvent of key.
+func (o *operation) prefix(key string) *PrefixEvent {
+	prefix := o.cache.prefix(key)
+
+	i, ok := o.index[prefix]
+	if !ok {
+		i = len(o.event.Prefixes)
+		o.index[prefix] = i
+		o.event.Prefixes = append(o.event.Prefixes, PrefixEvent{Prefix: prefix})
+	}
+
+	return &o.event.Prefixes[i]
+}
+
+func (o *operation) hit(key string)  { o.prefix(key).Hits++ }
+func (o *operation) miss(key string) { o.prefix(key).Misses++ }
+func (o *operation) set(key string)  { o.prefix(key).Sets++ }
+
+func (o *operation) fail(key string) {
+	o.prefix(key).Errors++
+	o.cache.errors.Add(1)
+}
+
+// finish updates the counters and calls the hooks in reverse order, so the
+// first hook wraps the others like a middleware.
+func (o *operation) finish(err error) {
+	o.event.Duration = time.Since(o.begin)
+	o.event.Err = err
+
+	if err != nil {
+		o.cache.errors.Add(1)
+	}
+
+	for i := range o.event.Prefixes {
+		pe := &o.event.Prefixes[i]
+		if err != nil {
+			pe.Errors = pe.Keys
+		}
+
+		counters := o.cache.counters(pe.Prefix)
+		counters.operations.Add(1)
+		counters.hits.Add(uint64(pe.Hits))
+		counters.misses.Add(uint64(pe.Misses))
+		counters.errors.Add(uint64(pe.Errors))
+		counters.sets.Add(uint64(pe.Sets))
+		counters.latency.Add(int64(o.event.Duration))
+	}
+
+	for i := len(o.cache.hooks) - 1; i >= 0; i-- {
+		o.cache.hooks[i].Finish(o.ctxs[i], o.event)
+	}
+}
+
+// unwrap returns the cache behind any InstrumentedCache wrappers.
+func unwrap(c Cache) Cache {
+	for {
+		w, ok := c.(interface{ Unwrap() Cache })
+		if !ok {
+			return c
+		}
+		c = w.Unwrap()
+	}
+}
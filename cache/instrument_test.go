@@ -0,0 +1,341 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/cache"
+	"github.com/DucTran999/shared-pkg/cache/cachetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHook keeps the events it receives.
+type recordingHook struct {
+	mu     sync.Mutex
+	events []cache.Event
+}
+
+func (h *recordingHook) Start(ctx context.Context, op cache.Op) context.Context {
+	return ctx
+}
+
+func (h *recordingHook) Finish(ctx context.Context, event cache.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.events = append(h.events, event)
+}
+
+// fakeMetric sums the values per label set.
+type fakeMetric struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newFakeMetric() *fakeMetric {
+	return &fakeMetric{values: make(map[string]float64)}
+}
+
+func (m *fakeMetric) Add(value float64, labels ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.values[strings.Join(labels, "/")] += value
+}
+
+func (m *fakeMetric) Observe(value float64, labels ...string) {
+	m.Add(1, labels...)
+}
+
+func (m *fakeMetric) get(labels ...string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.values[strings.Join(labels, "/")]
+}
+
+// fakeSpan records what the tracing hook does with it.
+type fakeSpan struct {
+	name       string
+	attributes map[string]any
+	err        error
+	ended      int
+}
+
+func (s *fakeSpan) SetAttribute(key string, value any) { s.attributes[key] = value }
+func (s *fakeSpan) RecordError(err error)              { s.err = err }
+func (s *fakeSpan) End()                               { s.ended++ }
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, cache.Span) {
+	span := &fakeSpan{name: name, attributes: make(map[string]any)}
+	t.spans = append(t.spans, span)
+
+	return ctx, span
+}
+
+func Test_InstrumentedCacheConformance(t *testing.T) {
+	cachetest.RunConformance(t, func(t *testing.T) cache.Cache {
+		return cache.NewInstrumentedCache(newTestCache(t))
+	})
+}
+
+func Test_InstrumentedCacheStats(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewInstrumentedCache(newTestCache(t))
+
+	require.NoError(t, c.Set(ctx, "product:1", "p1", time.Minute))
+	require.NoError(t, c.MSet(ctx,
+		cache.Item{Key: "user:1", Value: "u1"},
+		cache.Item{Key: "user:2", Value: "u2"},
+	))
+
+	_, err := c.Get(ctx, "product:1")
+	require.NoError(t, err)
+	_, err = c.Get(ctx, "product:2")
+	require.ErrorIs(t, err, cache.ErrKeyNotFound)
+
+	_, err = c.MGet(ctx, "user:1", "user:3", "product:1")
+	require.NoError(t, err)
+
+	require.Error(t, c.Set(ctx, "product:3", make(chan int), time.Minute))
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(3), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, uint64(3), stats.Sets)
+	assert.Equal(t, uint64(1), stats.Errors)
+	assert.InDelta(t, 0.6, stats.HitRatio(), 0.001)
+
+	product := stats.Prefixes["product"]
+	assert.Equal(t, uint64(5), product.Operations)
+	assert.Equal(t, uint64(2), product.Hits)
+	assert.Equal(t, uint64(1), product.Misses)
+	assert.Equal(t, uint64(1), product.Sets)
+	assert.Equal(t, uint64(1), product.Errors)
+	assert.Positive(t, product.Latency)
+	assert.Equal(t, product.Latency/5, product.MeanLatency())
+
+	user := stats.Prefixes["user"]
+	assert.Equal(t, uint64(2), user.Operations)
+	assert.Equal(t, uint64(1), user.Hits)
+	assert.Equal(t, uint64(1), user.Misses)
+	assert.Equal(t, uint64(2), user.Sets)
+	assert.InDelta(t, 0.5, user.HitRatio(), 0.001)
+}
+
+func Test_InstrumentedCacheKeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewInstrumentedCache(newTestCache(t), cache.WithKeyPrefix(func(key string) string {
+		return strings.SplitN(key, "/", 2)[0]
+	}))
+
+	require.NoError(t, c.Set(ctx, "sessions/abc", "1", time.Minute))
+	require.NoError(t, c.Set(ctx, "plain", "2", time.Minute))
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.Prefixes["sessions"].Sets)
+	assert.Equal(t, uint64(1), stats.Prefixes["plain"].Sets)
+	assert.Equal(t, "", cache.DefaultKeyPrefix("plain"))
+	assert.Equal(t, "product", cache.DefaultKeyPrefix("product:1:reviews"))
+}
+
+func Test_InstrumentedCacheHooks(t *testing.T) {
+	ctx := context.Background()
+	recorder := &recordingHook{}
+	c := cache.NewInstrumentedCache(newTestCache(t), cache.WithHooks(recorder))
+
+	require.NoError(t, c.Set(ctx, "product:1", "p1", time.Minute))
+	_, err := c.MGet(ctx, "product:1", "product:2", "user:1")
+	require.NoError(t, err)
+	require.NoError(t, c.Ping(ctx))
+
+	require.Len(t, recorder.events, 3)
+
+	set := recorder.events[0]
+	assert.Equal(t, cache.OpSet, set.Op)
+	assert.NoError(t, set.Err)
+	assert.Equal(t, []cache.PrefixEvent{{Prefix: "product", Keys: 1, Sets: 1}}, set.Prefixes)
+
+	mget := recorder.events[1]
+	assert.Equal(t, cache.OpMGet, mget.Op)
+	assert.Positive(t, mget.Duration)
+	assert.Equal(t, []cache.PrefixEvent{
+		{Prefix: "product", Keys: 2, Hits: 1, Misses: 1},
+		{Prefix: "user", Keys: 1, Misses: 1},
+	}, mget.Prefixes)
+
+	ping := recorder.events[2]
+	assert.Equal(t, cache.OpPing, ping.Op)
+	assert.Empty(t, ping.Prefixes)
+}
+
+func Test_MetricsHook(t *testing.T) {
+	ctx := context.Background()
+	hits, misses, sets, errs, latency := newFakeMetric(), newFakeMetric(), newFakeMetric(), newFakeMetric(), newFakeMetric()
+
+	c := cache.NewInstrumentedCache(newTestCache(t), cache.WithHooks(cache.NewMetricsHook(cache.Metrics{
+		Hits:    hits,
+		Misses:  misses,
+		Sets:    sets,
+		Errors:  errs,
+		Latency: latency,
+	})))
+
+	require.NoError(t, c.Set(ctx, "product:1", "p1", time.Minute))
+	_, err := c.Get(ctx, "product:1")
+	require.NoError(t, err)
+	_, err = c.Get(ctx, "product:2")
+	require.Error(t, err)
+	require.Error(t, c.Set(ctx, "product:3", make(chan int), time.Minute))
+	require.NoError(t, c.Ping(ctx))
+	_, err = c.MGet(ctx, "product:1", "user:1")
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(1), hits.get("get", "product"))
+	assert.Equal(t, float64(1), misses.get("get", "product"))
+	assert.Equal(t, float64(1), sets.get("set", "product"))
+	assert.Equal(t, float64(1), errs.get("set", "product"))
+	assert.Zero(t, errs.get("get", "product"), "misses are not errors")
+	assert.Equal(t, float64(2), latency.get("get", "product"))
+	assert.Equal(t, float64(2), latency.get("set", "product"))
+	assert.Equal(t, float64(1), latency.get("ping", ""))
+	assert.Equal(t, float64(1), latency.get("mget", ""), "observed once per operation")
+	assert.Zero(t, latency.get("mget", "product"))
+	assert.Equal(t, float64(1), hits.get("mget", "product"))
+	assert.Equal(t, float64(1), misses.get("mget", "user"))
+}
+
+func Test_TracingHook(t *testing.T) {
+	ctx := context.Background()
+	outer, inner := &fakeTracer{}, &fakeTracer{}
+	c := cache.NewInstrumentedCache(newTestCache(t), cache.WithHooks(
+		cache.NewTracingHook(outer),
+		cache.NewTracingHook(inner),
+	))
+
+	_, err := c.MGet(ctx, "product:1", "user:1")
+	require.NoError(t, err)
+	require.Error(t, c.Set(ctx, "product:1", make(chan int), time.Minute))
+
+	for _, tracer := range []*fakeTracer{outer, inner} {
+		require.Len(t, tracer.spans, 2)
+
+		mget := tracer.spans[0]
+		assert.Equal(t, "cache.mget", mget.name)
+		assert.Equal(t, 1, mget.ended, "each hook ends its own span")
+		assert.Equal(t, map[string]any{
+			"cache.operation": "mget",
+			"cache.prefixes":  "product,user",
+			"cache.keys":      2,
+			"cache.hits":      0,
+			"cache.misses":    2,
+		}, mget.attributes)
+		assert.NoError(t, mget.err, "misses are not errors")
+
+		set := tracer.spans[1]
+		assert.Equal(t, "cache.set", set.name)
+		assert.Equal(t, 1, set.ended)
+		assert.Error(t, set.err)
+	}
+}
+
+// evictionCounter counts the evictions reported to the hooks.
+type evictionCounter struct {
+	recordingHook
+
+	mu sync.Mutex
+	n  int
+}
+
+func (h *evictionCounter) Evicted() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.n++
+}
+
+func (h *evictionCounter) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.n
+}
+
+func Test_InstrumentedCacheEvictions(t *testing.T) {
+	ctx := context.Background()
+
+	small, err := cache.NewRistrettoCache(cache.RistrettoConfig{
+		NumCounters: 1e3,
+		MaxCost:     1 << 10, // a handful of items with the internal cost
+		BufferItems: 64,
+		Metrics:     true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { small.Close() })
+
+	hook := &evictionCounter{}
+	c := cache.NewInstrumentedCache(small, cache.WithHooks(hook))
+
+	for i := range 100 {
+		// Rejected values are not evictions, ignore them
+		c.Set(ctx, fmt.Sprintf("key:%d", i), "0123456789", time.Minute)
+	}
+
+	stats := c.Stats()
+	assert.Positive(t, stats.Evictions)
+	assert.Equal(t, int(stats.Evictions), hook.count())
+
+	metrics, ok := cache.RistrettoMetrics(c)
+	require.True(t, ok)
+	assert.Equal(t, stats.Evictions, metrics.KeysEvicted())
+}
+
+func Test_InstrumentedCacheUnwraps(t *testing.T) {
+	ctx := context.Background()
+
+	memory := cache.NewInstrumentedCache(newTestCache(t))
+	locker, err := cache.NewLocker(memory)
+	require.NoError(t, err)
+
+	lock, err := locker.Acquire(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release(ctx))
+
+	_, ok := cache.RedisClient(memory)
+	assert.False(t, ok)
+
+	_, ok = cache.RistrettoMetrics(memory)
+	assert.False(t, ok, "metrics are disabled by default")
+
+	redis := cache.NewInstrumentedCache(newTestRedisCache(t, newTestRedis(t)))
+	client, ok := cache.RedisClient(redis)
+	require.True(t, ok)
+	require.NoError(t, client.Ping(ctx).Err())
+}
+
+func Test_InstrumentedCacheRedisErrors(t *testing.T) {
+	ctx := context.Background()
+	mr := newTestRedis(t)
+	c := cache.NewInstrumentedCache(newTestRedisCache(t, mr))
+
+	mr.SetError("server down")
+
+	_, err := c.Get(ctx, "product:1")
+	require.Error(t, err)
+	require.False(t, errors.Is(err, cache.ErrKeyNotFound))
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.Errors)
+	assert.Equal(t, uint64(1), stats.Prefixes["product"].Errors)
+	assert.Zero(t, stats.Misses)
+}
@@ -0,0 +1,237 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// negativeMarker is stored in place of a value the loader reported as missing.
+	negativeMarker = "\x00\xffcache:notfound"
+
+	// envelopeHeader prefixes values stored with early refresh enabled. It is
+	// followed by the expiry and the load duration, 8 bytes each.
+	envelopeHeader = "\x00\xffcache:xfetch"
+	envelopeLength = len(envelopeHeader) + 16
+
+	DefaultEarlyRefreshBeta float64 = 1
+)
+
+// Loader loads the value of a key from the source of truth, such as a database.
+// It returns an error wrapping ErrKeyNotFound when the value does not exist.
+type Loader[T any] func(ctx context.Context) (T, error)
+
+// LoadOption is a functional option type for configuring GetOrLoad.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	negativeTTL  time.Duration
+	earlyRefresh bool
+	beta         float64
+}
+
+// WithNegativeTTL returns a LoadOption that remembers for ttl that the loader
+// reported the key as missing, so repeated lookups of a missing key do not
+// reach the source of truth.
+func WithNegativeTTL(ttl time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.negativeTTL = ttl
+	}
+}
+
+// WithEarlyRefresh returns a LoadOption that reloads a value in the background
+// shortly before it expires, so hot keys never expire under load. Keys whose
+// loader is slow are refreshed earlier, beta above 1 favors earlier refreshes
+// and a beta of zero or less uses 1.
+//
+// The decision is probabilistic (the XFetch algorithm), so instances sharing
+// a Redis do not all refresh the same key at once.
+func WithEarlyRefresh(beta float64) LoadOption {
+	return func(o *loadOptions) {
+		o.earlyRefresh = true
+		o.beta = beta
+		if beta <= 0 {
+			o.beta = DefaultEarlyRefreshBeta
+		}
+	}
+}
+
+// valueMeta is what early refresh needs to know about a stored value.
+type valueMeta struct {
+	expiry time.Time
+	delta  time.Duration
+}
+
+// shouldRefresh implements the XFetch check: refresh when now minus a random
+// multiple of the load duration is past the expiry.
+func (m valueMeta) shouldRefresh(now time.Time, beta float64) bool {
+	if m.expiry.IsZero() {
+		return false
+	}
+
+	// 1 - Float64 is in (0, 1], so the logarithm is finite and not positive
+	gap := time.Duration(float64(m.delta) * beta * -math.Log(1-rand.Float64()))
+
+	return !now.Add(gap).Before(m.expiry)
+}
+
+// GetOrLoad returns the value stored under key, or calls loader on a miss and
+// stores its result for ttl. Concurrent misses on the same key of the same
+// TypedCache share a single loader call.
+//
+// The loader runs without the cancellation of ctx since other callers may be
+// waiting on it, each caller still stops waiting when its own ctx is done.
+// When the cache itself fails the value is loaded and returned anyway.
+//
+// Example:
+//
+//	user, err := users.GetOrLoad(ctx, "user:42", time.Hour,
+//	    func(ctx context.Context) (User, error) {
+//	        return repo.FindUser(ctx, 42) // wraps cache.ErrKeyNotFound when missing
+//	    },
+//	    cache.WithNegativeTTL(time.Minute),
+//	    cache.WithEarlyRefresh(1),
+//	)
+func (t *TypedCache[T]) GetOrLoad(
+	ctx context.Context, key string, ttl time.Duration, loader Loader[T], opts ...LoadOption,
+) (T, error) {
+	var cfg loadOptions
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	raw, err := t.cache.Get(ctx, key)
+	if err == nil {
+		value, meta, err := t.decode(key, raw)
+		if err != nil {
+			return value, err
+		}
+
+		if cfg.earlyRefresh && meta.shouldRefresh(time.Now(), cfg.beta) {
+			go t.refresh(context.WithoutCancel(ctx), key, ttl, loader, cfg)
+		}
+
+		return value, nil
+	}
+
+	if !errors.Is(err, ErrKeyNotFound) {
+		log.Warn().Err(err).Str("key", key).Msg("cache read failed, loading value")
+	}
+
+	return t.load(ctx, key, ttl, loader, cfg)
+}
+
+// load calls the loader once per key for all concurrent callers.
+func (t *TypedCache[T]) load(
+	ctx context.Context, key string, ttl time.Duration, loader Loader[T], cfg loadOptions,
+) (T, error) {
+	loadCtx := context.WithoutCancel(ctx)
+
+	ch := t.group.DoChan(key, func() (any, error) {
+		return t.loadAndStore(loadCtx, key, ttl, loader, cfg)
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			var zero T
+			return zero, res.Err
+		}
+		// A nil interface value does not assert to T
+		v, _ := res.Val.(T)
+		return v, nil
+
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// refresh reloads a value before it expires, unless a load is already running.
+func (t *TypedCache[T]) refresh(ctx context.Context, key string, ttl time.Duration, loader Loader[T], cfg loadOptions) {
+	_, err, _ := t.group.Do(key, func() (any, error) {
+		return t.loadAndStore(ctx, key, ttl, loader, cfg)
+	})
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		log.Warn().Err(err).Str("key", key).Msg("failed to refresh cache value")
+	}
+}
+
+func (t *TypedCache[T]) loadAndStore(
+	ctx context.Context, key string, ttl time.Duration, loader Loader[T], cfg loadOptions,
+) (T, error) {
+	start := time.Now()
+
+	value, err := loader(ctx)
+	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) && cfg.negativeTTL > 0 {
+			if setErr := t.cache.Set(ctx, key, negativeMarker, cfg.negativeTTL); setErr != nil {
+				log.Warn().Err(setErr).Str("key", key).Msg("failed to cache missing value")
+			}
+		}
+		return value, err
+	}
+
+	b, err := t.codec.Marshal(&value)
+	if err != nil {
+		return value, fmt.Errorf("serialize cache value %s: %w", key, err)
+	}
+
+	if cfg.earlyRefresh && ttl > 0 {
+		b = encodeEnvelope(b, valueMeta{
+			expiry: time.Now().Add(ttl),
+			delta:  time.Since(start),
+		})
+	}
+
+	// The value is still good even if it could not be cached
+	if err := t.cache.Set(ctx, key, b, ttl); err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("failed to cache loaded value")
+	}
+
+	return value, nil
+}
+
+// decode turns a stored string back into a value, recognizing the negative
+// marker and the early refresh envelope written by GetOrLoad.
+func (t *TypedCache[T]) decode(key, raw string) (T, valueMeta, error) {
+	var (
+		value T
+		meta  valueMeta
+	)
+
+	if raw == negativeMarker {
+		return value, meta, fmt.Errorf("%w: %s", ErrKeyNotFound, key)
+	}
+
+	if len(raw) >= envelopeLength && strings.HasPrefix(raw, envelopeHeader) {
+		meta = valueMeta{
+			expiry: time.Unix(0, int64(binary.BigEndian.Uint64([]byte(raw[len(envelopeHeader):])))),
+			delta:  time.Duration(binary.BigEndian.Uint64([]byte(raw[len(envelopeHeader)+8:]))),
+		}
+		raw = raw[envelopeLength:]
+	}
+
+	if err := t.codec.Unmarshal([]byte(raw), &value); err != nil {
+		return value, meta, fmt.Errorf("deserialize cache value %s: %w", key, err)
+	}
+
+	return value, meta, nil
+}
+
+func encodeEnvelope(payload []byte, meta valueMeta) []byte {
+	b := make([]byte, 0, envelopeLength+len(payload))
+	b = append(b, envelopeHeader...)
+	b = binary.BigEndian.AppendUint64(b, uint64(meta.expiry.UnixNano()))
+	b = binary.BigEndian.AppendUint64(b, uint64(meta.delta))
+
+	return append(b, payload...)
+}
@@ -0,0 +1,141 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingLoader returns "v1", "v2", ... and counts its calls.
+func countingLoader(calls *atomic.Int32, delay time.Duration) cache.Loader[string] {
+	return func(ctx context.Context) (string, error) {
+		n := calls.Add(1)
+		time.Sleep(delay)
+		return fmt.Sprintf("v%d", n), nil
+	}
+}
+
+func Test_GetOrLoadCoalescesMisses(t *testing.T) {
+	ctx := context.Background()
+	values := cache.NewTypedCache[string](newTestCache(t))
+
+	var calls atomic.Int32
+	loader := countingLoader(&calls, 50*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			value, err := values.GetOrLoad(ctx, "key", time.Minute, loader)
+			assert.NoError(t, err)
+			assert.Equal(t, "v1", value)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Later reads hit the cache
+	value, err := values.GetOrLoad(ctx, "key", time.Minute, loader)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func Test_GetOrLoadNilInterface(t *testing.T) {
+	values := cache.NewTypedCache[any](newTestCache(t))
+
+	value, err := values.GetOrLoad(context.Background(), "key", time.Minute, func(ctx context.Context) (any, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func Test_GetOrLoadNegativeCaching(t *testing.T) {
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	loader := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return "", fmt.Errorf("user 42: %w", cache.ErrKeyNotFound)
+	}
+
+	t.Run("remembers missing values", func(t *testing.T) {
+		calls.Store(0)
+		values := cache.NewTypedCache[string](newTestCache(t))
+
+		for range 3 {
+			_, err := values.GetOrLoad(ctx, "user:42", time.Minute, loader, cache.WithNegativeTTL(time.Minute))
+			require.ErrorIs(t, err, cache.ErrKeyNotFound)
+		}
+		assert.Equal(t, int32(1), calls.Load())
+
+		_, err := values.Get(ctx, "user:42")
+		require.ErrorIs(t, err, cache.ErrKeyNotFound)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		calls.Store(0)
+		values := cache.NewTypedCache[string](newTestCache(t))
+
+		for range 3 {
+			_, err := values.GetOrLoad(ctx, "user:42", time.Minute, loader)
+			require.ErrorIs(t, err, cache.ErrKeyNotFound)
+		}
+		assert.Equal(t, int32(3), calls.Load())
+	})
+}
+
+func Test_GetOrLoadEarlyRefresh(t *testing.T) {
+	ctx := context.Background()
+	values := cache.NewTypedCache[string](newTestCache(t))
+
+	var calls atomic.Int32
+	loader := countingLoader(&calls, 20*time.Millisecond)
+
+	// A huge beta makes the refresh all but certain on the next read
+	opts := []cache.LoadOption{cache.WithEarlyRefresh(1e6)}
+
+	value, err := values.GetOrLoad(ctx, "key", time.Minute, loader, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	// The stale value is served while the refresh runs in the background
+	value, err = values.GetOrLoad(ctx, "key", time.Minute, loader, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	require.Eventually(t, func() bool {
+		value, err := values.Get(ctx, "key")
+		return err == nil && value == "v2"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_GetOrLoadCallerCanceled(t *testing.T) {
+	values := cache.NewTypedCache[string](newTestCache(t))
+
+	var calls atomic.Int32
+	loader := countingLoader(&calls, 100*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := values.GetOrLoad(ctx, "key", time.Minute, loader)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The load still completes for the other callers
+	require.Eventually(t, func() bool {
+		value, err := values.Get(context.Background(), "key")
+		return err == nil && value == "v1"
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,310 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const DefaultLockRetryInterval time.Duration = 100 * time.Millisecond
+
+// lockBackend stores the locks. A lock is held by one owner until it is
+// released or its ttl runs out, and every acquisition gets a fencing token
+// greater than the ones before it.
+type lockBackend interface {
+	acquire(ctx context.Context, key, owner string, ttl time.Duration) (token int64, ok bool, err error)
+	release(ctx context.Context, key, owner string) (bool, error)
+	extend(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+}
+
+// Locker hands out distributed locks, for example to run a cron job on a
+// single replica or to process a message once.
+type Locker struct {
+	backend lockBackend
+}
+
+// NewLocker returns a Locker that keeps its locks in the backend of c: Redis
+// for the Redis and tiered caches, so the locks are shared by every instance,
+// and process memory for the in-memory cache.
+//
+// Example:
+//
+//	locker, err := cache.NewLocker(c)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	err = locker.Run(ctx, "jobs:report", time.Minute, func(ctx context.Context) error {
+//	    return buildReport(ctx)
+//	})
+//	if errors.Is(err, cache.ErrLockNotAcquired) {
+//	    // Another replica runs the job
+//	}
+func NewLocker(c Cache) (*Locker, error) {
+	if client, ok := RedisClient(c); ok {
+		return &Locker{backend: &redisLocks{client: client}}, nil
+	}
+
+	if r, ok := unwrap(c).(*ristrettoCache); ok {
+		return &Locker{backend: r.locks}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrLockUnsupported, c)
+}
+
+// NewMemoryLocker returns a Locker that keeps its locks in process memory.
+// The locks only exclude the goroutines of this process, which suits tests
+// and single instance deployments.
+func NewMemoryLocker() *Locker {
+	return &Locker{backend: newMemoryLocks()}
+}
+
+// LockOption is a functional option type for configuring Acquire.
+type LockOption func(*lockOptions)
+
+type lockOptions struct {
+	retry     bool
+	interval  time.Duration
+	autoRenew bool
+}
+
+// WithLockRetry returns a LockOption that keeps trying to acquire a held lock
+// every interval until the context is done, instead of failing at once.
+// An interval of zero or less uses DefaultLockRetryInterval.
+func WithLockRetry(interval time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.retry = true
+		o.interval = interval
+		if interval <= 0 {
+			o.interval = DefaultLockRetryInterval
+		}
+	}
+}
+
+// WithAutoRenew returns a LockOption that extends the lock in the background
+// every third of its ttl until it is released. Lost reports when a renewal
+// fails and the lock may be held by someone else.
+func WithAutoRenew() LockOption {
+	return func(o *lockOptions) {
+		o.autoRenew = true
+	}
+}
+
+// Acquire takes the lock on key for ttl. It fails with ErrLockNotAcquired when
+// another owner holds the lock, unless WithLockRetry is set.
+func (l *Locker) Acquire(ctx context.Context, key string, ttl time.Duration, opts ...LockOption) (*Lock, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("lock %s: ttl must be positive", key)
+	}
+
+	var o lockOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	owner, err := newLockOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		token, ok, err := l.backend.acquire(ctx, key, owner, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+		}
+
+		if ok {
+			lock := &Lock{
+				backend: l.backend,
+				key:     key,
+				owner:   owner,
+				token:   token,
+				ttl:     ttl,
+				lost:    make(chan struct{}),
+			}
+
+			if o.autoRenew {
+				lock.startRenew()
+			}
+
+			return lock, nil
+		}
+
+		if !o.retry {
+			return nil, fmt.Errorf("%w: %s", ErrLockNotAcquired, key)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %s: %w", ErrLockNotAcquired, key, ctx.Err())
+		case <-time.After(o.interval):
+		}
+	}
+}
+
+// Run acquires the lock on key, renews it while fn runs and releases it when
+// fn returns. The context passed to fn is canceled if the lock is lost.
+func (l *Locker) Run(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error, opts ...LockOption) error {
+	lock, err := l.Acquire(ctx, key, ttl, append(opts, WithAutoRenew())...)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-lock.Lost():
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+
+	fnErr := fn(runCtx)
+
+	// Release even when the caller context is done
+	releaseErr := lock.Release(context.WithoutCancel(ctx))
+	if errors.Is(releaseErr, ErrLockNotHeld) {
+		// The lock expired or was lost, there is nothing left to release
+		releaseErr = nil
+	}
+
+	return errors.Join(fnErr, releaseErr)
+}
+
+// Lock is a lock held on a key. It is safe for concurrent use.
+type Lock struct {
+	backend lockBackend
+	key     string
+	owner   string
+	token   int64
+	ttl     time.Duration
+
+	mu       sync.Mutex
+	released bool
+	stop     chan struct{}
+	renewed  chan struct{}
+	lost     chan struct{}
+}
+
+// Key returns the locked key.
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Token returns the fencing token of the lock. Tokens grow with every
+// acquisition of the key, so a storage that remembers the highest token it
+// has seen can reject the writes of an owner whose lock already expired.
+func (l *Lock) Token() int64 {
+	return l.token
+}
+
+// Lost is closed when an automatic renewal fails and the lock may no longer
+// be held. It is never closed without WithAutoRenew.
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Extend resets the ttl of the lock. It fails with ErrLockNotHeld when the
+// lock expired or was released.
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("lock %s: ttl must be positive", l.key)
+	}
+
+	ok, err := l.backend.extend(ctx, l.key, l.owner, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to extend lock %s: %w", l.key, err)
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrLockNotHeld, l.key)
+	}
+
+	return nil
+}
+
+// Release stops the automatic renewal and frees the lock. Only the owner can
+// release it, so a lock that expired and was taken by someone else is left
+// alone and ErrLockNotHeld is returned.
+func (l *Lock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.released {
+		return fmt.Errorf("%w: %s", ErrLockNotHeld, l.key)
+	}
+	l.released = true
+
+	if l.stop != nil {
+		close(l.stop)
+		<-l.renewed
+	}
+
+	ok, err := l.backend.release(ctx, l.key, l.owner)
+	if err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.key, err)
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrLockNotHeld, l.key)
+	}
+
+	return nil
+}
+
+// startRenew extends the lock every third of its ttl. Transient errors are
+// retried on the next tick, the lock is lost once a renewal is refused or the
+// last successful one is older than the ttl.
+func (l *Lock) startRenew() {
+	l.stop = make(chan struct{})
+	l.renewed = make(chan struct{})
+
+	go func() {
+		defer close(l.renewed)
+
+		ticker := time.NewTicker(max(l.ttl/3, time.Millisecond))
+		defer ticker.Stop()
+
+		lastRenew := time.Now()
+		for {
+			select {
+			case <-ticker.C:
+			case <-l.stop:
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3+time.Second)
+			err := l.Extend(ctx, l.ttl)
+			cancel()
+
+			switch {
+			case err == nil:
+				lastRenew = time.Now()
+				continue
+			case errors.Is(err, ErrLockNotHeld), time.Since(lastRenew) >= l.ttl:
+				log.Warn().Err(err).Str("key", l.key).Msg("lock lost")
+				close(l.lost)
+				return
+			default:
+				log.Debug().Err(err).Str("key", l.key).Msg("failed to renew lock, retrying")
+			}
+		}
+	}()
+}
+
+func newLockOwner() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock owner: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
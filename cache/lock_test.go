@@ -0,0 +1,263 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lockers() map[string]func(t *testing.T) *cache.Locker {
+	newLocker := func(t *testing.T, c cache.Cache) *cache.Locker {
+		locker, err := cache.NewLocker(c)
+		require.NoError(t, err)
+		return locker
+	}
+
+	return map[string]func(t *testing.T) *cache.Locker{
+		"memory": func(t *testing.T) *cache.Locker {
+			return newLocker(t, newTestCache(t))
+		},
+		"redis": func(t *testing.T) *cache.Locker {
+			return newLocker(t, newTestRedisCache(t, newTestRedis(t)))
+		},
+		"tiered": func(t *testing.T) *cache.Locker {
+			return newLocker(t, newTestTieredCache(t, newTestRedis(t), time.Minute))
+		},
+	}
+}
+
+func Test_LockIsExclusive(t *testing.T) {
+	for name, newLocker := range lockers() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			locker := newLocker(t)
+
+			lock, err := locker.Acquire(ctx, "job", time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "job", lock.Key())
+
+			_, err = locker.Acquire(ctx, "job", time.Minute)
+			require.ErrorIs(t, err, cache.ErrLockNotAcquired)
+
+			other, err := locker.Acquire(ctx, "other", time.Minute)
+			require.NoError(t, err, "locks on other keys are independent")
+			require.NoError(t, other.Release(ctx))
+
+			require.NoError(t, lock.Release(ctx))
+			require.ErrorIs(t, lock.Release(ctx), cache.ErrLockNotHeld, "release twice")
+
+			next, err := locker.Acquire(ctx, "job", time.Minute)
+			require.NoError(t, err)
+			assert.Greater(t, next.Token(), lock.Token(), "fencing tokens grow")
+			require.NoError(t, next.Release(ctx))
+		})
+	}
+}
+
+func Test_LockExpires(t *testing.T) {
+	for name, newLocker := range lockers() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			locker := newLocker(t)
+
+			stale, err := locker.Acquire(ctx, "job", 100*time.Millisecond)
+			require.NoError(t, err)
+
+			var next *cache.Lock
+			require.Eventually(t, func() bool {
+				next, err = locker.Acquire(ctx, "job", time.Minute)
+				return err == nil
+			}, 2*time.Second, 20*time.Millisecond)
+
+			// The expired owner cannot touch the new lock
+			require.ErrorIs(t, stale.Extend(ctx, time.Minute), cache.ErrLockNotHeld)
+			require.ErrorIs(t, stale.Release(ctx), cache.ErrLockNotHeld)
+
+			_, err = locker.Acquire(ctx, "job", time.Minute)
+			require.ErrorIs(t, err, cache.ErrLockNotAcquired)
+			require.NoError(t, next.Release(ctx))
+		})
+	}
+}
+
+func Test_LockExtendAndAutoRenew(t *testing.T) {
+	for name, newLocker := range lockers() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			locker := newLocker(t)
+
+			extended, err := locker.Acquire(ctx, "extended", 150*time.Millisecond)
+			require.NoError(t, err)
+			require.NoError(t, extended.Extend(ctx, time.Minute))
+
+			renewed, err := locker.Acquire(ctx, "renewed", 150*time.Millisecond, cache.WithAutoRenew())
+			require.NoError(t, err)
+
+			time.Sleep(500 * time.Millisecond)
+
+			for _, key := range []string{"extended", "renewed"} {
+				_, err = locker.Acquire(ctx, key, time.Minute)
+				require.ErrorIs(t, err, cache.ErrLockNotAcquired, key)
+			}
+
+			select {
+			case <-renewed.Lost():
+				t.Fatal("renewed lock must not be lost")
+			default:
+			}
+
+			require.NoError(t, extended.Release(ctx))
+			require.NoError(t, renewed.Release(ctx))
+		})
+	}
+}
+
+func Test_LockRetry(t *testing.T) {
+	for name, newLocker := range lockers() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			locker := newLocker(t)
+
+			lock, err := locker.Acquire(ctx, "job", time.Minute)
+			require.NoError(t, err)
+
+			time.AfterFunc(100*time.Millisecond, func() { lock.Release(ctx) })
+
+			next, err := locker.Acquire(ctx, "job", time.Minute, cache.WithLockRetry(10*time.Millisecond))
+			require.NoError(t, err)
+			require.NoError(t, next.Release(ctx))
+
+			held, err := locker.Acquire(ctx, "held", time.Minute)
+			require.NoError(t, err)
+			t.Cleanup(func() { held.Release(context.Background()) })
+
+			timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+
+			_, err = locker.Acquire(timeoutCtx, "held", time.Minute, cache.WithLockRetry(10*time.Millisecond))
+			require.ErrorIs(t, err, cache.ErrLockNotAcquired)
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+		})
+	}
+}
+
+func Test_LockerRunOnce(t *testing.T) {
+	for name, newLocker := range lockers() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			locker := newLocker(t)
+
+			var runs, skipped atomic.Int32
+			var wg sync.WaitGroup
+			for range 5 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					err := locker.Run(ctx, "cron", time.Second, func(ctx context.Context) error {
+						runs.Add(1)
+						time.Sleep(100 * time.Millisecond)
+						return nil
+					})
+					if err != nil {
+						assert.ErrorIs(t, err, cache.ErrLockNotAcquired)
+						skipped.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+
+			assert.Equal(t, int32(1), runs.Load())
+			assert.Equal(t, int32(4), skipped.Load())
+
+			// Run released the lock
+			lock, err := locker.Acquire(ctx, "cron", time.Minute)
+			require.NoError(t, err)
+			require.NoError(t, lock.Release(ctx))
+		})
+	}
+}
+
+func Test_LockLostCancelsRun(t *testing.T) {
+	ctx := context.Background()
+	mr := newTestRedis(t)
+
+	locker, err := cache.NewLocker(newTestRedisCache(t, mr))
+	require.NoError(t, err)
+
+	err = locker.Run(ctx, "cron", 150*time.Millisecond, func(ctx context.Context) error {
+		// Another process wrongly takes over the lock
+		mr.Set("lock:{cron}", "intruder")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+			return nil
+		}
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	owner, err := mr.Get("lock:{cron}")
+	require.NoError(t, err)
+	assert.Equal(t, "intruder", owner, "the lock of another owner is not released")
+}
+
+func Test_NewMemoryLocker(t *testing.T) {
+	ctx := context.Background()
+	locker := cache.NewMemoryLocker()
+
+	lock, err := locker.Acquire(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.Positive(t, lock.Token())
+
+	_, err = locker.Acquire(ctx, "job", time.Minute)
+	require.ErrorIs(t, err, cache.ErrLockNotAcquired)
+}
+
+func Test_RedisLockFence(t *testing.T) {
+	ctx := context.Background()
+	mr := newTestRedis(t)
+	locker, err := cache.NewLocker(newTestRedisCache(t, mr))
+	require.NoError(t, err)
+
+	lock, err := locker.Acquire(ctx, "message:1", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release(ctx))
+
+	assert.Positive(t, mr.TTL("lock:{message:1}:fence"), "the counter must not live forever")
+
+	// The counter expires a while after the last acquisition
+	mr.SetTime(time.Now().Add(8 * 24 * time.Hour))
+	mr.FastForward(8 * 24 * time.Hour)
+	require.False(t, mr.Exists("lock:{message:1}:fence"))
+
+	next, err := locker.Acquire(ctx, "message:1", time.Minute)
+	require.NoError(t, err)
+	assert.Greater(t, next.Token(), lock.Token(), "tokens grow after the counter expired")
+}
+
+func Test_LockSubMillisecondTTL(t *testing.T) {
+	for name, newLocker := range lockers() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			locker := newLocker(t)
+
+			lock, err := locker.Acquire(ctx, "job", 500*time.Microsecond)
+			require.NoError(t, err)
+
+			err = lock.Extend(ctx, 500*time.Microsecond)
+			if err != nil {
+				require.ErrorIs(t, err, cache.ErrLockNotHeld, "only an expired lock may fail")
+			}
+
+			require.Error(t, lock.Extend(ctx, 0))
+		})
+	}
+}
@@ -2,7 +2,9 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/ristretto/v2"
@@ -10,6 +12,14 @@ import (
 
 type ristrettoCache struct {
 	cache *ristretto.Cache[string, string]
+
+	evictMu        sync.RWMutex
+	evictListeners []func()
+
+	// locks backs the Lockers created from this cache
+	locks *memoryLocks
+
+	tags *tagIndex
 }
 
 // RistrettoConfig holds configuration for the in-memory Ristretto cache
@@ -17,6 +27,10 @@ type RistrettoConfig struct {
 	NumCounters int64
 	MaxCost     int64
 	BufferItems int64
+
+	// Metrics enables the Ristretto metrics, see RistrettoMetrics. They add
+	// a small overhead to every operation.
+	Metrics bool
 }
 
 // DefaultRistrettoConfig returns sensible default values for Ristretto
@@ -34,44 +48,102 @@ func NewRistrettoCache(config ...RistrettoConfig) (Cache, error) {
 		cfg = config[0]
 	}
 
+	return newRistrettoCache(cfg)
+}
+
+func newRistrettoCache(cfg RistrettoConfig) (*ristrettoCache, error) {
+	r := &ristrettoCache{locks: newMemoryLocks()}
+	r.tags = newTagIndex(r.keyTTL)
+
 	c, err := ristretto.NewCache(&ristretto.Config[string, string]{
 		NumCounters: cfg.NumCounters, // number of keys to track frequency
 		MaxCost:     cfg.MaxCost,     // maximum cost of cache
 		BufferItems: cfg.BufferItems, // number of keys per Get buffer
+		Metrics:     cfg.Metrics,
+		// Ristretto also calls OnEvict for the keys its ttl cleanup removes,
+		// which are expirations and not evictions. Clear would call it too,
+		// but the cache never clears Ristretto.
+		OnEvict: func(item *ristretto.Item[string]) {
+			if !item.Expiration.IsZero() && !item.Expiration.After(time.Now()) {
+				return
+			}
+			r.evicted()
+		},
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &ristrettoCache{cache: c}, nil
+	r.cache = c
+
+	return r, nil
+}
+
+// RistrettoMetrics returns the Ristretto metrics of an in-memory or tiered
+// cache created with RistrettoConfig.Metrics, or false otherwise.
+func RistrettoMetrics(c Cache) (*ristretto.Metrics, bool) {
+	switch c := unwrap(c).(type) {
+	case *ristrettoCache:
+		return c.cache.Metrics, c.cache.Metrics != nil
+	case *tieredCache:
+		return c.l1.cache.Metrics, c.l1.cache.Metrics != nil
+	default:
+		return nil, false
+	}
+}
+
+// onEvict registers fn to be called when Ristretto evicts a key to make room.
+func (r *ristrettoCache) onEvict(fn func()) {
+	r.evictMu.Lock()
+	defer r.evictMu.Unlock()
+
+	r.evictListeners = append(r.evictListeners, fn)
+}
+
+func (r *ristrettoCache) evicted() {
+	r.evictMu.RLock()
+	defer r.evictMu.RUnlock()
+
+	for _, fn := range r.evictListeners {
+		fn()
+	}
 }
 
 func (r *ristrettoCache) Get(ctx context.Context, key string) (string, error) {
 	val, found := r.cache.Get(key)
 	if !found {
-		return "", fmt.Errorf("key not found: %s", key)
+		return "", fmt.Errorf("%w: %s", ErrKeyNotFound, key)
 	}
 
 	return val, nil
 }
 
 func (r *ristrettoCache) Set(ctx context.Context, key string, value any, expiration time.Duration) error {
-	strVal := fmt.Sprintf("%v", value)
-	if strVal == "" {
-		return fmt.Errorf("value cannot be empty")
+	if err := r.set(key, value, expiration); err != nil {
+		return err
 	}
 
-	// Use string length as a reasonable cost metric
-	cost := int64(len(strVal))
+	// Ensure value is visible immediately
+	r.cache.Wait()
+
+	return nil
+}
+
+// set queues a value in Ristretto, the caller waits for it to be visible.
+func (r *ristrettoCache) set(key string, value any, expiration time.Duration) error {
+	strVal, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	// Use string length as a reasonable cost metric, empty values still cost one
+	cost := max(int64(len(strVal)), 1)
 	ok := r.cache.SetWithTTL(key, strVal, cost, expiration)
 	if !ok {
-		return fmt.Errorf("failed to set key: %s", key)
+		return fmt.Errorf("%w: %s", ErrNotStored, key)
 	}
 
-	// Ensure value is visible immediately
-	r.cache.Wait()
-
 	return nil
 }
 
@@ -83,6 +155,54 @@ func (r *ristrettoCache) Del(ctx context.Context, keys ...string) error {
 	return nil
 }
 
+func (r *ristrettoCache) MGet(ctx context.Context, keys ...string) ([]Result, error) {
+	results := make([]Result, len(keys))
+	for i, key := range keys {
+		val, err := r.Get(ctx, key)
+		results[i] = Result{Key: key, Value: val, Err: err}
+	}
+
+	return results, nil
+}
+
+func (r *ristrettoCache) MSet(ctx context.Context, items ...Item) error {
+	var errs []error
+	for _, item := range items {
+		if err := r.set(item.Key, item.Value, item.TTL); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Wait once for the whole batch
+	r.cache.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (r *ristrettoCache) Exists(ctx context.Context, keys ...string) ([]bool, error) {
+	exists := make([]bool, len(keys))
+	for i, key := range keys {
+		_, exists[i] = r.cache.Get(key)
+	}
+
+	return exists, nil
+}
+
+func (r *ristrettoCache) SetWithTags(ctx context.Context, key string, value any, expiration time.Duration, tags ...string) error {
+	r.tags.add(key, expiration, tags...)
+
+	return r.Set(ctx, key, value, expiration)
+}
+
+// keyTTL returns the remaining ttl of key, zero when it has none.
+func (r *ristrettoCache) keyTTL(key string) (time.Duration, bool) {
+	return r.cache.GetTTL(key)
+}
+
+func (r *ristrettoCache) InvalidateTag(ctx context.Context, tags ...string) error {
+	return r.Del(ctx, r.tags.take(tags...)...)
+}
+
 func (r *ristrettoCache) Ping(ctx context.Context) error {
 	// Ristretto does not have a ping method, but we can check if the cache is initialized
 	if r.cache == nil {
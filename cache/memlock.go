@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// lockSweepInterval is how often expired locks and fencing counters are
+// dropped from memory.
+const lockSweepInterval = time.Minute
+
+type memoryLock struct {
+	owner   string
+	expires time.Time
+}
+
+// memoryFence is the fencing counter of a key. Like the Redis counter it
+// expires fenceTTL after the last acquisition and starts again from the clock
+// in microseconds, so tokens keep growing.
+type memoryFence struct {
+	token   int64
+	expires time.Time
+}
+
+// memoryLocks keeps the locks in process memory. It does not use Ristretto,
+// whose admission policy may drop a lock or refuse to store it.
+type memoryLocks struct {
+	mu        sync.Mutex
+	locks     map[string]memoryLock
+	fences    map[string]memoryFence
+	lastSweep time.Time
+}
+
+func newMemoryLocks() *memoryLocks {
+	return &memoryLocks{
+		locks:  make(map[string]memoryLock),
+		fences: make(map[string]memoryFence),
+	}
+}
+
+func (m *memoryLocks) acquire(ctx context.Context, key, owner string, ttl time.Duration) (int64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	if _, held := m.held(key); held {
+		return 0, false, nil
+	}
+
+	m.locks[key] = memoryLock{owner: owner, expires: now.Add(ttl)}
+
+	fence, ok := m.fences[key]
+	if !ok || now.After(fence.expires) {
+		fence.token = now.UnixMicro()
+	}
+	fence.token++
+	fence.expires = now.Add(fenceTTL)
+	m.fences[key] = fence
+
+	return fence.token, true, nil
+}
+
+func (m *memoryLocks) release(ctx context.Context, key, owner string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lock, held := m.held(key); !held || lock.owner != owner {
+		return false, nil
+	}
+
+	delete(m.locks, key)
+
+	return true, nil
+}
+
+func (m *memoryLocks) extend(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lock, held := m.held(key)
+	if !held || lock.owner != owner {
+		return false, nil
+	}
+
+	lock.expires = time.Now().Add(ttl)
+	m.locks[key] = lock
+
+	return true, nil
+}
+
+// held returns the lock on key unless it expired. The caller holds m.mu.
+func (m *memoryLocks) held(key string) (memoryLock, bool) {
+	lock, ok := m.locks[key]
+	if ok && time.Now().After(lock.expires) {
+		delete(m.locks, key)
+		return memoryLock{}, false
+	}
+
+	return lock, ok
+}
+
+// sweep drops the expired locks and fencing counters, at most once per
+// lockSweepInterval. The caller holds m.mu.
+func (m *memoryLocks) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < lockSweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for key, lock := range m.locks {
+		if now.After(lock.expires) {
+			delete(m.locks, key)
+		}
+	}
+
+	for key, fence := range m.fences {
+		if now.After(fence.expires) {
+			delete(m.fences, key)
+		}
+	}
+}
@@ -1,40 +1,42 @@
 package cache
 
 import (
+	"cmp"
 	"context"
-	"encoding"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
 )
 
 type redisCache struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
+// NewRedisCache connects to Redis and checks the connection with a PING.
+// The topology follows the config: a single node, Sentinel or Cluster.
 func NewRedisCache(config Config) (Cache, error) {
-	// Initialize Redis client
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     net.JoinHostPort(config.Host, strconv.Itoa(config.Port)),
-		Password: config.Password,
-		DB:       config.DB,
+	return newRedisCache(config)
+}
 
-		// Set connection pool options
-		DialTimeout:  5 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-		PoolSize:     10,
-		MinIdleConns: 5,
-	})
+func newRedisCache(config Config) (*redisCache, error) {
+	config, err := config.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize Redis client
+	rdb := redis.NewUniversalClient(config.universalOptions())
 
 	// Test the connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
@@ -43,6 +45,56 @@ func NewRedisCache(config Config) (Cache, error) {
 	}, nil
 }
 
+// RedisClient returns the Redis client behind c, so other packages can run
+// commands the Cache interface does not offer on the same connection pool.
+// It returns false when c does not use Redis.
+func RedisClient(c Cache) (redis.UniversalClient, bool) {
+	switch c := unwrap(c).(type) {
+	case *redisCache:
+		return c.client, true
+	case *tieredCache:
+		return c.l2.client, true
+	default:
+		return nil, false
+	}
+}
+
+// universalOptions maps the config to go-redis options, filling the defaults.
+func (c Config) universalOptions() *redis.UniversalOptions {
+	addrs := c.Addrs
+	if len(addrs) == 0 {
+		port := c.Port
+		if port == 0 {
+			port = defaultRedisPort
+		}
+		addrs = []string{net.JoinHostPort(c.Host, strconv.Itoa(port))}
+	}
+
+	return &redis.UniversalOptions{
+		Addrs:      addrs,
+		MasterName: c.MasterName,
+		Username:   c.Username,
+		Password:   c.Password,
+		DB:         c.DB,
+
+		SentinelUsername: c.SentinelUsername,
+		SentinelPassword: c.SentinelPassword,
+		IsClusterMode:    c.IsCluster,
+		TLSConfig:        c.TLSConfig,
+
+		// Set connection pool options
+		DialTimeout:     cmp.Or(c.DialTimeout, 5*time.Second),
+		ReadTimeout:     cmp.Or(c.ReadTimeout, 3*time.Second),
+		WriteTimeout:    cmp.Or(c.WriteTimeout, 3*time.Second),
+		PoolSize:        cmp.Or(c.PoolSize, 10),
+		MinIdleConns:    cmp.Or(c.MinIdleConns, 5),
+		MaxIdleConns:    c.MaxIdleConns,
+		PoolTimeout:     c.PoolTimeout,
+		ConnMaxIdleTime: c.ConnMaxIdleTime,
+		ConnMaxLifetime: c.ConnMaxLifetime,
+	}
+}
+
 func (r *redisCache) Get(ctx context.Context, key string) (string, error) {
 	val, err := r.client.Get(ctx, key).Result()
 	if err != nil {
@@ -58,30 +110,304 @@ func (r *redisCache) Get(ctx context.Context, key string) (string, error) {
 }
 
 func (r *redisCache) Set(ctx context.Context, key string, value any, expiration time.Duration) error {
-	var b []byte
-	var err error
-
-	switch v := value.(type) {
-	case encoding.BinaryMarshaler:
-		b, err = v.MarshalBinary()
-	case string:
-		b = []byte(v)
-	case []byte:
-		b = v
-	default:
-		b, err = json.Marshal(v) // fallback to JSON
-	}
+	strVal, err := encodeValue(value)
 	if err != nil {
-		return fmt.Errorf("serialize cache value: %w", err)
+		return err
 	}
 
-	return r.client.Set(ctx, key, string(b), expiration).Err()
+	return r.client.Set(ctx, key, strVal, expiration).Err()
 }
 
 func (r *redisCache) Del(ctx context.Context, keys ...string) error {
+	// DEL without keys is a Redis error, the in-memory cache accepts it
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// Keys of a multi-key DEL must share a hash slot in a cluster,
+	// so delete them one by one and let the pipeline route each command
+	if _, ok := r.client.(*redis.ClusterClient); ok && len(keys) > 1 {
+		_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Del(ctx, key)
+			}
+			return nil
+		})
+		return err
+	}
+
 	return r.client.Del(ctx, keys...).Err()
 }
 
+// MGet reads the keys in a single pipeline of GET commands, so the error of
+// each key is reported on its own.
+func (r *redisCache) MGet(ctx context.Context, keys ...string) ([]Result, error) {
+	if len(keys) == 0 {
+		return []Result{}, nil
+	}
+
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+	if err := pipelineError(err); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(keys))
+	for i, cmd := range cmds {
+		val, err := cmd.Result()
+		if err == redis.Nil {
+			err = fmt.Errorf("%w: %s", ErrKeyNotFound, keys[i])
+		}
+		results[i] = Result{Key: keys[i], Value: val, Err: err}
+	}
+
+	return results, nil
+}
+
+// getWithTTL reads the keys like MGet, along with the time they have left.
+// The ttl of a key without expiration is negative, see localFillTTL.
+func (r *redisCache) getWithTTL(ctx context.Context, keys ...string) ([]Result, []time.Duration, error) {
+	gets := make([]*redis.StringCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			gets[i] = pipe.Get(ctx, key)
+			ttls[i] = pipe.PTTL(ctx, key)
+		}
+		return nil
+	})
+	if err := pipelineError(err); err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]Result, len(keys))
+	remaining := make([]time.Duration, len(keys))
+	for i, cmd := range gets {
+		val, err := cmd.Result()
+		if err == redis.Nil {
+			err = fmt.Errorf("%w: %s", ErrKeyNotFound, keys[i])
+		}
+		results[i] = Result{Key: keys[i], Value: val, Err: err}
+		remaining[i] = ttls[i].Val()
+	}
+
+	return results, remaining, nil
+}
+
+// MSet writes the items in a single pipeline, each with its own expiration.
+func (r *redisCache) MSet(ctx context.Context, items ...Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	values := make([]string, len(items))
+	for i, item := range items {
+		strVal, err := encodeValue(item.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", item.Key, err)
+		}
+		values[i] = strVal
+	}
+
+	cmds, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, item := range items {
+			pipe.Set(ctx, item.Key, values[i], item.TTL)
+		}
+		return nil
+	})
+	if err != nil {
+		var errs []error
+		for _, cmd := range cmds {
+			if cmd.Err() != nil {
+				errs = append(errs, cmd.Err())
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// Exists checks the keys in a single pipeline of EXISTS commands.
+func (r *redisCache) Exists(ctx context.Context, keys ...string) ([]bool, error) {
+	if len(keys) == 0 {
+		return []bool{}, nil
+	}
+
+	cmds := make([]*redis.IntCmd, len(keys))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Exists(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists := make([]bool, len(keys))
+	for i, cmd := range cmds {
+		exists[i] = cmd.Val() > 0
+	}
+
+	return exists, nil
+}
+
+// SetWithTags attaches the tags before storing the value, so a failure
+// leaves a stale tag entry rather than a key no tag can invalidate.
+func (r *redisCache) SetWithTags(ctx context.Context, key string, value any, expiration time.Duration, tags ...string) error {
+	strVal, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	cmds := make([]*redis.Cmd, len(tags))
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, tag := range tags {
+			cmds[i] = tagScript.Eval(ctx, pipe, []string{tagKey(tag)}, key, tagTTL(expiration))
+		}
+		pipe.Set(ctx, key, strVal, expiration)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The value is stored, a failed pruning only leaves stale keys in a tag
+	for i, tag := range tags {
+		res, _ := cmds[i].Int64Slice()
+		if len(res) == 2 && res[0] > 0 {
+			if err := r.pruneTag(ctx, tag, res[1]); err != nil {
+				log.Warn().Err(err).Str("tag", tag).Msg("failed to prune cache tag")
+			}
+		}
+	}
+
+	return nil
+}
+
+// pruneTag checks the keys of a tag whose tagged expiry passed at now, the
+// server time in microseconds. The ones that no longer exist are removed, the
+// others Set kept alive get their current expiry as score.
+func (r *redisCache) pruneTag(ctx context.Context, tag string, now int64) error {
+	members, err := r.client.ZRangeByScoreWithScores(ctx, tagKey(tag), &redis.ZRangeBy{
+		Min:   "0",
+		Max:   strconv.FormatInt(now, 10),
+		Count: tagPruneBatch,
+	}).Result()
+	if err != nil || len(members) == 0 {
+		return err
+	}
+
+	ttls := make([]*redis.DurationCmd, len(members))
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, member := range members {
+			ttls[i] = pipe.PTTL(ctx, member.Member.(string))
+		}
+		return nil
+	})
+	if err := pipelineError(err); err != nil {
+		return err
+	}
+
+	args := make([]any, 0, 3*len(members))
+	for i, member := range members {
+		ttl, err := ttls[i].Result()
+		if err != nil {
+			return err
+		}
+
+		score := ""
+		switch {
+		case ttl == -1:
+			score = strconv.FormatInt(-now, 10)
+		case ttl >= 0:
+			score = strconv.FormatInt(now+ttl.Microseconds(), 10)
+		}
+		args = append(args, member.Member, strconv.FormatFloat(member.Score, 'f', -1, 64), score)
+	}
+
+	return pruneScript.Run(ctx, r.client, []string{tagKey(tag)}, args...).Err()
+}
+
+func (r *redisCache) InvalidateTag(ctx context.Context, tags ...string) error {
+	_, err := r.invalidateTags(ctx, tags...)
+	return err
+}
+
+// invalidateTags deletes the keys of the tags and returns them. The keys are
+// removed from the tag sets only once deleted, so a failed call can be
+// retried, and keys tagged again meanwhile stay tracked.
+func (r *redisCache) invalidateTags(ctx context.Context, tags ...string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	cmds := make([]*redis.ZSliceCmd, len(tags))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, tag := range tags {
+			cmds[i] = pipe.ZRangeWithScores(ctx, tagKey(tag), 0, -1)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, cmd := range cmds {
+		for _, member := range cmd.Val() {
+			keys = append(keys, member.Member.(string))
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	if err := r.Del(ctx, keys...); err != nil {
+		return nil, err
+	}
+
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, tag := range tags {
+			members := cmds[i].Val()
+			if len(members) == 0 {
+				continue
+			}
+
+			args := make([]any, 0, 2*len(members))
+			for _, member := range members {
+				args = append(args, member.Member, strconv.FormatFloat(member.Score, 'f', -1, 64))
+			}
+			untagScript.Eval(ctx, pipe, []string{tagKey(tag)}, args...)
+		}
+		return nil
+	})
+
+	return keys, err
+}
+
+// pipelineError returns the error of a pipeline that failed as a whole.
+// Errors of single commands, such as a missing key, are left to the caller.
+func pipelineError(err error) error {
+	if err == nil || err == redis.Nil {
+		return nil
+	}
+
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		return nil
+	}
+
+	return err
+}
+
 func (r *redisCache) Ping(ctx context.Context) error {
 	return r.client.Ping(ctx).Err()
 }
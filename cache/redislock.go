@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The lock and its fencing counter share a hash tag, so the scripts touch a
+// single slot in a cluster.
+const (
+	lockKeyPrefix = "lock:{"
+	lockKeySuffix = "}"
+	fenceSuffix   = "}:fence"
+
+	// fenceTTL is how long the fencing counter of a key outlives its last
+	// acquisition, so locks on short-lived keys do not leave a counter behind
+	// forever.
+	fenceTTL = 7 * 24 * time.Hour
+)
+
+var (
+	// acquireScript sets the lock when it is free and returns the next
+	// fencing token, or 0 when the lock is held. A missing counter starts
+	// from the server clock in microseconds, so tokens keep growing after
+	// the counter expired.
+	// ARGV: owner, lock ttl and counter ttl in milliseconds.
+	acquireScript = redis.NewScript(`
+redis.replicate_commands()
+
+if not redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return 0
+end
+
+if redis.call("EXISTS", KEYS[2]) == 0 then
+	local time = redis.call("TIME")
+	redis.call("SET", KEYS[2], time[1] .. string.format("%06d", tonumber(time[2])))
+end
+
+local token = redis.call("INCR", KEYS[2])
+redis.call("PEXPIRE", KEYS[2], ARGV[3])
+
+return token
+`)
+
+	// releaseScript deletes the lock only when it still belongs to the owner.
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+	// extendScript resets the ttl only when the lock still belongs to the owner.
+	extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+)
+
+// redisLocks keeps the locks in Redis. The fencing counter outlives the lock
+// by fenceTTL, so tokens keep growing after the lock expires.
+type redisLocks struct {
+	client redis.UniversalClient
+}
+
+func (r *redisLocks) acquire(ctx context.Context, key, owner string, ttl time.Duration) (int64, bool, error) {
+	token, err := acquireScript.Run(ctx, r.client,
+		[]string{lockKeyPrefix + key + lockKeySuffix, lockKeyPrefix + key + fenceSuffix},
+		owner, lockTTL(ttl), fenceTTL.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return 0, false, err
+	}
+
+	return token, token > 0, nil
+}
+
+func (r *redisLocks) release(ctx context.Context, key, owner string) (bool, error) {
+	n, err := releaseScript.Run(ctx, r.client, []string{lockKeyPrefix + key + lockKeySuffix}, owner).Int64()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+func (r *redisLocks) extend(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	n, err := extendScript.Run(ctx, r.client, []string{lockKeyPrefix + key + lockKeySuffix}, owner, lockTTL(ttl)).Int64()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// lockTTL converts a ttl to milliseconds, rounding sub-millisecond ttls up
+// since Redis rejects an expiration of 0.
+func lockTTL(ttl time.Duration) int64 {
+	return max(ttl.Milliseconds(), 1)
+}
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// tagKeyPrefix prefixes the Redis sorted sets holding the keys of a tag.
+	tagKeyPrefix = "cache:tag:"
+
+	// minTagPrune is the size a tag reaches before its expired keys are pruned.
+	minTagPrune = 64
+
+	// tagPruneBatch bounds the keys of a Redis tag checked in one pruning pass.
+	tagPruneBatch = 256
+)
+
+// tagScript adds a key to the sorted set of a tag, scored by its expiry in
+// microseconds, or by the negated current time when it has no expiration so
+// a new write of the key always changes its score. The set does not expire,
+// since Set may keep a key alive past the expiration it was tagged with; it
+// is pruned of the keys that no longer exist instead, see pruneTag.
+// It returns the number of keys whose expiry passed and the server time.
+// ARGV: key, ttl in milliseconds or 0.
+var tagScript = redis.NewScript(`
+redis.replicate_commands()
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local ttl = tonumber(ARGV[2])
+
+local score = -now
+if ttl > 0 then
+	score = now + ttl * 1000
+end
+redis.call("ZADD", KEYS[1], score, ARGV[1])
+
+return {redis.call("ZCOUNT", KEYS[1], 0, now), now}
+`)
+
+// untagScript removes the keys from the set of a tag, unless their score
+// changed because they were tagged again since it was read.
+// ARGV: key, score, key, score...
+var untagScript = redis.NewScript(`
+for i = 1, #ARGV, 2 do
+	local score = redis.call("ZSCORE", KEYS[1], ARGV[i])
+	if score and tonumber(score) == tonumber(ARGV[i + 1]) then
+		redis.call("ZREM", KEYS[1], ARGV[i])
+	end
+end
+
+return 1
+`)
+
+// pruneScript removes the keys that no longer exist from the set of a tag and
+// moves the score of the others to their current expiry, unless their score
+// changed because they were tagged again since it was read. An empty new
+// score removes the key.
+// ARGV: key, score, new score, key, score, new score...
+var pruneScript = redis.NewScript(`
+for i = 1, #ARGV, 3 do
+	local score = redis.call("ZSCORE", KEYS[1], ARGV[i])
+	if score and tonumber(score) == tonumber(ARGV[i + 1]) then
+		if ARGV[i + 2] == "" then
+			redis.call("ZREM", KEYS[1], ARGV[i])
+		else
+			redis.call("ZADD", KEYS[1], ARGV[i + 2], ARGV[i])
+		end
+	end
+end
+
+return 1
+`)
+
+func tagKey(tag string) string {
+	return tagKeyPrefix + tag
+}
+
+// tagTTL converts an expiration to the milliseconds tagScript expects,
+// rounding sub-millisecond expirations up like SET does.
+func tagTTL(expiration time.Duration) int64 {
+	if expiration <= 0 {
+		return 0
+	}
+
+	return max(expiration.Milliseconds(), 1)
+}
+
+// tagIndex tracks the keys of each tag for the in-memory cache.
+type tagIndex struct {
+	mu   sync.Mutex
+	tags map[string]*tagMembers
+
+	// keyTTL returns the remaining ttl of a cached key, zero when it has
+	// none, and false when the key is gone.
+	keyTTL func(key string) (time.Duration, bool)
+}
+
+// tagMembers maps the keys of a tag to their expiry, zero when they have none.
+type tagMembers struct {
+	keys    map[string]time.Time
+	pruneAt int
+}
+
+func newTagIndex(keyTTL func(key string) (time.Duration, bool)) *tagIndex {
+	return &tagIndex{tags: make(map[string]*tagMembers), keyTTL: keyTTL}
+}
+
+// add attaches the tags to key.
+func (x *tagIndex) add(key string, expiration time.Duration, tags ...string) {
+	var expiry time.Time
+	if expiration > 0 {
+		expiry = time.Now().Add(expiration)
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for _, tag := range tags {
+		members, ok := x.tags[tag]
+		if !ok {
+			members = &tagMembers{keys: make(map[string]time.Time), pruneAt: minTagPrune}
+			x.tags[tag] = members
+		}
+
+		members.keys[key] = expiry
+
+		if len(members.keys) >= members.pruneAt {
+			members.prune(x.keyTTL)
+		}
+	}
+}
+
+// take removes the tags and returns their keys.
+func (x *tagIndex) take(tags ...string) []string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	var keys []string
+	for _, tag := range tags {
+		members, ok := x.tags[tag]
+		if !ok {
+			continue
+		}
+
+		for key := range members.keys {
+			keys = append(keys, key)
+		}
+		delete(x.tags, tag)
+	}
+
+	return keys
+}
+
+// prune forgets the keys that are gone, then waits for the tag to double in
+// size before the next pass so adding keys stays cheap. Only the keys whose
+// tagged expiry passed are checked, the others moved to their current expiry
+// since Set may have kept them alive.
+func (m *tagMembers) prune(keyTTL func(key string) (time.Duration, bool)) {
+	now := time.Now()
+	for key, expiry := range m.keys {
+		if expiry.IsZero() || !now.After(expiry) {
+			continue
+		}
+
+		ttl, ok := keyTTL(key)
+		switch {
+		case !ok:
+			delete(m.keys, key)
+		case ttl <= 0:
+			m.keys[key] = time.Time{}
+		default:
+			m.keys[key] = now.Add(ttl)
+		}
+	}
+
+	m.pruneAt = max(2*len(m.keys), minTagPrune)
+}
@@ -0,0 +1,123 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RedisTagKeepsKeysOverwrittenBySet(t *testing.T) {
+	ctx := context.Background()
+	mr := newTestRedis(t)
+	c := newTestRedisCache(t, mr)
+
+	require.NoError(t, c.SetWithTags(ctx, "product:1", "1", time.Second, "listing"))
+	require.NoError(t, c.SetWithTags(ctx, "product:2", "2", time.Second, "listing"))
+	assert.Zero(t, mr.TTL("cache:tag:listing"), "the tag must outlive keys kept alive by Set")
+
+	// Set keeps the key alive past the expiration it was tagged with
+	require.NoError(t, c.Set(ctx, "product:1", "1", time.Hour))
+
+	mr.SetTime(time.Now().Add(2 * time.Second))
+	mr.FastForward(2 * time.Second)
+
+	require.NoError(t, c.SetWithTags(ctx, "product:new", "3", time.Minute, "listing"))
+
+	members, err := mr.ZMembers("cache:tag:listing")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"product:1", "product:new"}, members)
+
+	require.NoError(t, c.InvalidateTag(ctx, "listing"))
+	assert.False(t, mr.Exists("product:1"))
+	assert.False(t, mr.Exists("cache:tag:listing"))
+}
+
+func Test_MemoryTagKeepsKeysOverwrittenBySet(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache(t)
+
+	require.NoError(t, c.SetWithTags(ctx, "product:0", "0", 50*time.Millisecond, "listing"))
+	require.NoError(t, c.Set(ctx, "product:0", "0", time.Hour))
+
+	// Enough keys for the tag to be pruned by the next write
+	for i := 1; i < 63; i++ {
+		require.NoError(t, c.SetWithTags(ctx, fmt.Sprintf("product:%d", i), "1", 50*time.Millisecond, "listing"))
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, c.SetWithTags(ctx, "product:new", "2", time.Minute, "listing"))
+
+	require.NoError(t, c.InvalidateTag(ctx, "listing"))
+
+	_, err := c.Get(ctx, "product:0")
+	require.ErrorIs(t, err, cache.ErrKeyNotFound, "pruning must keep the key Set kept alive")
+}
+
+func Test_RedisTagPrunesExpiredKeys(t *testing.T) {
+	ctx := context.Background()
+	mr := newTestRedis(t)
+	c := newTestRedisCache(t, mr)
+
+	require.NoError(t, c.SetWithTags(ctx, "product:pinned", "0", 0, "listing"))
+	for i := range 10 {
+		require.NoError(t, c.SetWithTags(ctx, fmt.Sprintf("product:%d", i), "1", time.Second, "listing"))
+	}
+
+	// Scripts read the clock of miniredis, keys expire with FastForward
+	mr.SetTime(time.Now().Add(2 * time.Second))
+	mr.FastForward(2 * time.Second)
+
+	require.NoError(t, c.SetWithTags(ctx, "product:new", "2", time.Minute, "listing"))
+
+	members, err := mr.ZMembers("cache:tag:listing")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"product:pinned", "product:new"}, members)
+
+	require.NoError(t, c.InvalidateTag(ctx, "listing"))
+	assert.False(t, mr.Exists("cache:tag:listing"))
+	assert.False(t, mr.Exists("product:pinned"))
+}
+
+func Test_TieredInvalidateTagReachesOtherInstances(t *testing.T) {
+	ctx := context.Background()
+	mr := newTestRedis(t)
+	writer := newTestTieredCache(t, mr, time.Minute)
+	reader := newTestTieredCache(t, mr, time.Minute)
+
+	require.NoError(t, writer.SetWithTags(ctx, "product:1", "v1", time.Minute, "product:1"))
+
+	// The reader now holds a local copy
+	actual, err := reader.Get(ctx, "product:1")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", actual)
+
+	require.NoError(t, writer.InvalidateTag(ctx, "product:1"))
+
+	require.Eventually(t, func() bool {
+		_, err := reader.Get(ctx, "product:1")
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = reader.Get(ctx, "product:1")
+	require.ErrorIs(t, err, cache.ErrKeyNotFound)
+}
+
+func Test_TypedCacheTags(t *testing.T) {
+	ctx := context.Background()
+	users := cache.NewTypedCache[testUser](newTestCache(t))
+
+	require.NoError(t, users.SetWithTags(ctx, "user:42", testUser{ID: 42}, time.Minute, "team:7"))
+	require.NoError(t, users.SetWithTags(ctx, "user:43", testUser{ID: 43}, time.Minute, "team:7"))
+
+	require.NoError(t, users.InvalidateTag(ctx, "team:7"))
+
+	for _, key := range []string{"user:42", "user:43"} {
+		_, err := users.Get(ctx, key)
+		require.ErrorIs(t, err, cache.ErrKeyNotFound, key)
+	}
+}
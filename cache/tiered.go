@@ -0,0 +1,437 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultL1TTL               time.Duration = 30 * time.Second
+	DefaultInvalidationChannel string        = "cache:invalidate"
+)
+
+// TieredConfig holds the settings of the local tier of a two-tier cache.
+type TieredConfig struct {
+	// L1TTL is the longest time a value stays in the local cache. It bounds
+	// how stale a value can get when an invalidation message is missed.
+	L1TTL time.Duration
+
+	// Ristretto configures the local cache, zero values use DefaultRistrettoConfig.
+	Ristretto RistrettoConfig
+
+	// InvalidationChannel is the Redis pub/sub channel used to drop local
+	// entries on other instances. Instances sharing data must use the same one.
+	InvalidationChannel string
+}
+
+// invalidation is the message published when keys change.
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+// tieredCache reads from a local Ristretto cache first and falls back to Redis.
+type tieredCache struct {
+	l1 *ristrettoCache
+	l2 *redisCache
+
+	l1TTL      time.Duration
+	channel    string
+	instanceID string
+
+	pubsub    *redis.PubSub
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewTieredCache creates a two-tier cache: reads hit the local Ristretto cache
+// first, then Redis, and values read from Redis are kept locally for L1TTL,
+// or until they expire in Redis when that comes sooner.
+// Set and Del write through to Redis and publish an invalidation so the other
+// instances drop their local copy. A failed publish is logged, not returned,
+// since the write itself succeeded.
+//
+// The Redis settings come from config, the local tier from config.Tiered, or
+// the defaults when it is nil:
+//   - L1TTL: 30s
+//   - Ristretto: DefaultRistrettoConfig()
+//   - InvalidationChannel: cache:invalidate
+//
+// Example:
+//
+//	c, err := NewTieredCache(Config{
+//	    Host:   "localhost",
+//	    Port:   6379,
+//	    Tiered: &TieredConfig{L1TTL: 10 * time.Second},
+//	})
+func NewTieredCache(config Config) (Cache, error) {
+	var tiered TieredConfig
+	if config.Tiered != nil {
+		tiered = *config.Tiered
+	}
+
+	if tiered.L1TTL <= 0 {
+		tiered.L1TTL = DefaultL1TTL
+	}
+
+	if tiered.Ristretto == (RistrettoConfig{}) {
+		tiered.Ristretto = DefaultRistrettoConfig()
+	}
+
+	if tiered.InvalidationChannel == "" {
+		tiered.InvalidationChannel = DefaultInvalidationChannel
+	}
+
+	instanceID, err := newInstanceID()
+	if err != nil {
+		return nil, err
+	}
+
+	l2, err := newRedisCache(config)
+	if err != nil {
+		return nil, err
+	}
+
+	l1, err := newRistrettoCache(tiered.Ristretto)
+	if err != nil {
+		l2.Close()
+		return nil, err
+	}
+
+	// Wait for the subscription so no invalidation is missed after returning
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pubsub := l2.client.Subscribe(ctx, tiered.InvalidationChannel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		l1.Close()
+		l2.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", tiered.InvalidationChannel, err)
+	}
+
+	c := &tieredCache{
+		l1:         l1,
+		l2:         l2,
+		l1TTL:      tiered.L1TTL,
+		channel:    tiered.InvalidationChannel,
+		instanceID: instanceID,
+		pubsub:     pubsub,
+		done:       make(chan struct{}),
+	}
+
+	go c.listen()
+
+	return c, nil
+}
+
+func (c *tieredCache) Get(ctx context.Context, key string) (string, error) {
+	if val, err := c.l1.Get(ctx, key); err == nil {
+		return val, nil
+	}
+
+	results, ttls, err := c.l2.getWithTTL(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	res := results[0]
+	if res.Err != nil {
+		return "", res.Err
+	}
+
+	if ttl, ok := c.localFillTTL(ttls[0]); ok {
+		if err := c.l1.Set(ctx, key, res.Value, ttl); err != nil {
+			log.Debug().Err(err).Str("key", key).Msg("failed to populate local cache")
+		}
+	}
+
+	return res.Value, nil
+}
+
+func (c *tieredCache) Set(ctx context.Context, key string, value any, expiration time.Duration) error {
+	strVal, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	if err := c.l2.Set(ctx, key, strVal, expiration); err != nil {
+		return err
+	}
+
+	if err := c.l1.Set(ctx, key, strVal, c.localTTL(expiration)); err != nil {
+		// A stale local value would shadow the new one
+		c.l1.Del(ctx, key)
+	}
+
+	c.publish(ctx, key)
+
+	return nil
+}
+
+func (c *tieredCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := c.l2.Del(ctx, keys...); err != nil {
+		return err
+	}
+
+	if err := c.l1.Del(ctx, keys...); err != nil {
+		return err
+	}
+
+	c.publish(ctx, keys...)
+
+	return nil
+}
+
+func (c *tieredCache) MGet(ctx context.Context, keys ...string) ([]Result, error) {
+	results, err := c.l1.MGet(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the local misses from Redis in one round trip
+	var missing []int
+	var missingKeys []string
+	for i, res := range results {
+		if !res.Found() {
+			missing = append(missing, i)
+			missingKeys = append(missingKeys, res.Key)
+		}
+	}
+
+	if len(missingKeys) == 0 {
+		return results, nil
+	}
+
+	remote, ttls, err := c.l2.getWithTTL(ctx, missingKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	fill := make([]Item, 0, len(remote))
+	for j, res := range remote {
+		results[missing[j]] = res
+		if !res.Found() {
+			continue
+		}
+
+		if ttl, ok := c.localFillTTL(ttls[j]); ok {
+			fill = append(fill, Item{Key: res.Key, Value: res.Value, TTL: ttl})
+		}
+	}
+
+	if err := c.l1.MSet(ctx, fill...); err != nil {
+		log.Debug().Err(err).Msg("failed to populate local cache")
+	}
+
+	return results, nil
+}
+
+func (c *tieredCache) MSet(ctx context.Context, items ...Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	local := make([]Item, len(items))
+	keys := make([]string, len(items))
+	for i, item := range items {
+		strVal, err := encodeValue(item.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", item.Key, err)
+		}
+
+		local[i] = Item{Key: item.Key, Value: strVal, TTL: c.localTTL(item.TTL)}
+		keys[i] = item.Key
+	}
+
+	if err := c.l2.MSet(ctx, items...); err != nil {
+		// Some items may have been written, drop every local copy
+		c.l1.Del(ctx, keys...)
+		c.publish(ctx, keys...)
+		return err
+	}
+
+	if err := c.l1.MSet(ctx, local...); err != nil {
+		c.l1.Del(ctx, keys...)
+	}
+
+	c.publish(ctx, keys...)
+
+	return nil
+}
+
+// Exists trusts the local cache for the keys it holds and asks Redis for the others.
+func (c *tieredCache) Exists(ctx context.Context, keys ...string) ([]bool, error) {
+	exists, err := c.l1.Exists(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []int
+	var missingKeys []string
+	for i, ok := range exists {
+		if !ok {
+			missing = append(missing, i)
+			missingKeys = append(missingKeys, keys[i])
+		}
+	}
+
+	if len(missingKeys) == 0 {
+		return exists, nil
+	}
+
+	remote, err := c.l2.Exists(ctx, missingKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, ok := range remote {
+		exists[missing[j]] = ok
+	}
+
+	return exists, nil
+}
+
+func (c *tieredCache) SetWithTags(ctx context.Context, key string, value any, expiration time.Duration, tags ...string) error {
+	strVal, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	// The tags live in Redis only, local copies are dropped by key
+	if err := c.l2.SetWithTags(ctx, key, strVal, expiration, tags...); err != nil {
+		return err
+	}
+
+	if err := c.l1.Set(ctx, key, strVal, c.localTTL(expiration)); err != nil {
+		c.l1.Del(ctx, key)
+	}
+
+	c.publish(ctx, key)
+
+	return nil
+}
+
+func (c *tieredCache) InvalidateTag(ctx context.Context, tags ...string) error {
+	keys, err := c.l2.invalidateTags(ctx, tags...)
+	if len(keys) == 0 {
+		return err
+	}
+
+	c.l1.Del(ctx, keys...)
+
+	c.publish(ctx, keys...)
+
+	return err
+}
+
+func (c *tieredCache) Ping(ctx context.Context) error {
+	return c.l2.Ping(ctx)
+}
+
+func (c *tieredCache) Close() error {
+	var err error
+
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = errors.Join(c.pubsub.Close(), c.l2.Close(), c.l1.Close())
+	})
+
+	return err
+}
+
+// onEvict reports the keys evicted from the local tier.
+func (c *tieredCache) onEvict(fn func()) {
+	c.l1.onEvict(fn)
+}
+
+// localTTL keeps the local copy from outliving the Redis one.
+func (c *tieredCache) localTTL(expiration time.Duration) time.Duration {
+	if expiration > 0 && expiration < c.l1TTL {
+		return expiration
+	}
+
+	return c.l1TTL
+}
+
+// localFillTTL returns how long a value read from Redis can be kept locally:
+// L1TTL, or less when the key expires sooner in Redis. A key about to expire,
+// or that expired between the reads, is not kept.
+func (c *tieredCache) localFillTTL(remaining time.Duration) (time.Duration, bool) {
+	// PTTL answers -1 for a key without expiration and -2 for a missing one
+	if remaining == -1 {
+		return c.l1TTL, true
+	}
+
+	if remaining < time.Millisecond {
+		return 0, false
+	}
+
+	return min(remaining, c.l1TTL), true
+}
+
+// publish tells the other instances to drop their local copy of the keys.
+// The write already succeeded, so a failure is only logged: it delays their
+// update until the local entries expire.
+func (c *tieredCache) publish(ctx context.Context, keys ...string) {
+	msg, err := json.Marshal(invalidation{Origin: c.instanceID, Keys: keys})
+	if err == nil {
+		err = c.l2.client.Publish(ctx, c.channel, msg).Err()
+	}
+
+	if err != nil {
+		log.Warn().Err(err).Strs("keys", keys).Msg("failed to publish cache invalidation")
+	}
+}
+
+// listen drops the local entries invalidated by other instances.
+func (c *tieredCache) listen() {
+	ch := c.pubsub.Channel()
+
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			var inv invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+				log.Warn().Err(err).Str("channel", c.channel).Msg("invalid cache invalidation message")
+				continue
+			}
+
+			// Our own writes already updated the local cache
+			if inv.Origin == c.instanceID {
+				continue
+			}
+
+			c.l1.Del(context.Background(), inv.Keys...)
+
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func newInstanceID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate cache instance id: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,143 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/cache"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTieredCache(t *testing.T, mr *miniredis.Miniredis, l1TTL time.Duration) cache.Cache {
+	config := redisConfig(t, mr)
+	config.Tiered = &cache.TieredConfig{
+		L1TTL: l1TTL,
+		Ristretto: cache.RistrettoConfig{
+			NumCounters: 1e4,
+			MaxCost:     1 << 20,
+			BufferItems: 64,
+		},
+	}
+
+	c, err := cache.NewCache(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+
+	return c
+}
+
+func Test_TieredCacheServesFromL1(t *testing.T) {
+	ctx := context.Background()
+	mr := newTestRedis(t)
+	c := newTestTieredCache(t, mr, time.Minute)
+
+	require.NoError(t, mr.Set("product:1", "from redis"))
+
+	actual, err := c.Get(ctx, "product:1")
+	require.NoError(t, err)
+	assert.Equal(t, "from redis", actual)
+
+	// A change made behind the cache is not seen until the local entry expires
+	require.NoError(t, mr.Set("product:1", "changed"))
+
+	actual, err = c.Get(ctx, "product:1")
+	require.NoError(t, err)
+	assert.Equal(t, "from redis", actual)
+}
+
+func Test_TieredCacheL1Expires(t *testing.T) {
+	ctx := context.Background()
+	mr := newTestRedis(t)
+	c := newTestTieredCache(t, mr, 100*time.Millisecond)
+
+	require.NoError(t, mr.Set("product:1", "old"))
+	_, err := c.Get(ctx, "product:1")
+	require.NoError(t, err)
+
+	require.NoError(t, mr.Set("product:1", "new"))
+
+	require.Eventually(t, func() bool {
+		actual, err := c.Get(ctx, "product:1")
+		return err == nil && actual == "new"
+	}, time.Second, 20*time.Millisecond)
+}
+
+func Test_TieredCacheL1FollowsRedisExpiration(t *testing.T) {
+	testcases := []struct {
+		name string
+		read func(ctx context.Context, c cache.Cache) error
+	}{
+		{
+			name: "get",
+			read: func(ctx context.Context, c cache.Cache) error {
+				_, err := c.Get(ctx, "product:1")
+				return err
+			},
+		},
+		{
+			name: "mget",
+			read: func(ctx context.Context, c cache.Cache) error {
+				results, err := c.MGet(ctx, "product:1")
+				if err != nil {
+					return err
+				}
+				return results[0].Err
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			mr := newTestRedis(t)
+			writer := newTestTieredCache(t, mr, time.Minute)
+			reader := newTestTieredCache(t, mr, time.Minute)
+
+			require.NoError(t, writer.Set(ctx, "product:1", "v1", time.Second))
+
+			// The reader reads the key just before it expires in Redis
+			mr.FastForward(900 * time.Millisecond)
+			require.NoError(t, tc.read(ctx, reader))
+
+			mr.FastForward(200 * time.Millisecond)
+			require.False(t, mr.Exists("product:1"))
+
+			require.Eventually(t, func() bool {
+				return errors.Is(tc.read(ctx, reader), cache.ErrKeyNotFound)
+			}, time.Second, 20*time.Millisecond, "the local copy must not outlive the key")
+		})
+	}
+}
+
+func Test_TieredCacheInvalidatesOtherInstances(t *testing.T) {
+	ctx := context.Background()
+	mr := newTestRedis(t)
+	a := newTestTieredCache(t, mr, time.Minute)
+	b := newTestTieredCache(t, mr, time.Minute)
+
+	require.NoError(t, a.Set(ctx, "product:1", "v1", time.Hour))
+
+	// b keeps v1 in its local cache
+	actual, err := b.Get(ctx, "product:1")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", actual)
+
+	require.NoError(t, a.Set(ctx, "product:1", "v2", time.Hour))
+	require.Eventually(t, func() bool {
+		actual, err := b.Get(ctx, "product:1")
+		return err == nil && actual == "v2"
+	}, time.Second, 10*time.Millisecond, "set on a must invalidate b")
+
+	require.NoError(t, a.Del(ctx, "product:1"))
+	require.Eventually(t, func() bool {
+		_, err := b.Get(ctx, "product:1")
+		return err != nil
+	}, time.Second, 10*time.Millisecond, "del on a must invalidate b")
+
+	_, err = b.Get(ctx, "product:1")
+	require.ErrorIs(t, err, cache.ErrKeyNotFound)
+}
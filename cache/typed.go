@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// TypedCache stores values of type T in a Cache with a Codec, so a value
+// reads back as the same T on every backend.
+type TypedCache[T any] struct {
+	cache Cache
+	codec Codec
+
+	// group coalesces the concurrent loads of GetOrLoad per key.
+	group singleflight.Group
+}
+
+// NewTypedCache wraps c for values of type T. The codec defaults to JSONCodec.
+//
+// Example:
+//
+//	users := cache.NewTypedCache[User](c, cache.MsgpackCodec)
+//
+//	err := users.Set(ctx, "user:42", user, time.Hour)
+//	user, err := users.Get(ctx, "user:42")
+func NewTypedCache[T any](c Cache, codec ...Codec) *TypedCache[T] {
+	tc := &TypedCache[T]{
+		cache: c,
+		codec: JSONCodec,
+	}
+
+	if len(codec) > 0 && codec[0] != nil {
+		tc.codec = codec[0]
+	}
+
+	return tc
+}
+
+// Get returns the value stored under key, or ErrKeyNotFound.
+func (t *TypedCache[T]) Get(ctx context.Context, key string) (T, error) {
+	var value T
+
+	raw, err := t.cache.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	value, _, err = t.decode(key, raw)
+
+	return value, err
+}
+
+// Set encodes value with the codec and stores it under key.
+func (t *TypedCache[T]) Set(ctx context.Context, key string, value T, expiration time.Duration) error {
+	// Pass a pointer so methods such as MarshalBinary on the pointer receiver are found
+	b, err := t.codec.Marshal(&value)
+	if err != nil {
+		return fmt.Errorf("serialize cache value %s: %w", key, err)
+	}
+
+	return t.cache.Set(ctx, key, b, expiration)
+}
+
+// SetWithTags encodes value with the codec and stores it under key with tags,
+// see Cache.SetWithTags.
+func (t *TypedCache[T]) SetWithTags(ctx context.Context, key string, value T, expiration time.Duration, tags ...string) error {
+	b, err := t.codec.Marshal(&value)
+	if err != nil {
+		return fmt.Errorf("serialize cache value %s: %w", key, err)
+	}
+
+	return t.cache.SetWithTags(ctx, key, b, expiration, tags...)
+}
+
+// Del removes one or more keys from the cache.
+func (t *TypedCache[T]) Del(ctx context.Context, keys ...string) error {
+	return t.cache.Del(ctx, keys...)
+}
+
+// InvalidateTag removes every key attached to one of the tags.
+func (t *TypedCache[T]) InvalidateTag(ctx context.Context, tags ...string) error {
+	return t.cache.InvalidateTag(ctx, tags...)
+}
+
+// Cache returns the underlying untyped cache.
+func (t *TypedCache[T]) Cache() Cache {
+	return t.cache
+}
@@ -0,0 +1,139 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testUser struct {
+	ID        int               `json:"id"`
+	Name      string            `json:"name"`
+	Tags      []string          `json:"tags"`
+	Meta      map[string]string `json:"meta"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+// testVersion implements the binary marshaling interfaces like generated protobuf types.
+type testVersion struct {
+	Major, Minor uint16
+}
+
+func (v *testVersion) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint16(b, v.Major)
+	binary.BigEndian.PutUint16(b[2:], v.Minor)
+	return b, nil
+}
+
+func (v *testVersion) UnmarshalBinary(b []byte) error {
+	if len(b) != 4 {
+		return errors.New("invalid version")
+	}
+	v.Major = binary.BigEndian.Uint16(b)
+	v.Minor = binary.BigEndian.Uint16(b[2:])
+	return nil
+}
+
+func newTestCache(t *testing.T) cache.Cache {
+	c, err := cache.NewRistrettoCache(cache.RistrettoConfig{
+		NumCounters: 1e4,
+		MaxCost:     1 << 20,
+		BufferItems: 64,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+
+	return c
+}
+
+func Test_TypedCacheCodecs(t *testing.T) {
+	user := testUser{
+		ID:        42,
+		Name:      "Ada",
+		Tags:      []string{"admin", "beta"},
+		Meta:      map[string]string{"plan": "pro"},
+		CreatedAt: time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC),
+	}
+
+	testcases := []struct {
+		name  string
+		codec cache.Codec
+	}{
+		{name: "json", codec: cache.JSONCodec},
+		{name: "gob", codec: cache.GobCodec},
+		{name: "msgpack", codec: cache.MsgpackCodec},
+	}
+
+	backends := map[string]func(t *testing.T) cache.Cache{
+		"ristretto": newTestCache,
+		"redis": func(t *testing.T) cache.Cache {
+			return newTestRedisCache(t, newTestRedis(t))
+		},
+	}
+
+	for backend, newCache := range backends {
+		for _, tc := range testcases {
+			t.Run(backend+"/"+tc.name, func(t *testing.T) {
+				ctx := context.Background()
+				users := cache.NewTypedCache[testUser](newCache(t), tc.codec)
+
+				require.NoError(t, users.Set(ctx, "user:42", user, time.Minute))
+
+				actual, err := users.Get(ctx, "user:42")
+				require.NoError(t, err)
+				assert.Equal(t, user.ID, actual.ID)
+				assert.Equal(t, user.Name, actual.Name)
+				assert.Equal(t, user.Tags, actual.Tags)
+				assert.Equal(t, user.Meta, actual.Meta)
+				assert.True(t, user.CreatedAt.Equal(actual.CreatedAt))
+			})
+		}
+	}
+}
+
+func Test_TypedCacheBinaryCodec(t *testing.T) {
+	ctx := context.Background()
+
+	versions := cache.NewTypedCache[testVersion](newTestCache(t), cache.BinaryCodec)
+	require.NoError(t, versions.Set(ctx, "version", testVersion{Major: 1, Minor: 2}, time.Minute))
+
+	actual, err := versions.Get(ctx, "version")
+	require.NoError(t, err)
+	assert.Equal(t, testVersion{Major: 1, Minor: 2}, actual)
+
+	users := cache.NewTypedCache[testUser](newTestCache(t), cache.BinaryCodec)
+	err = users.Set(ctx, "user", testUser{}, time.Minute)
+	require.ErrorIs(t, err, cache.ErrUnsupportedValue)
+}
+
+func Test_TypedCacheDefaultCodec(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache(t)
+
+	users := cache.NewTypedCache[testUser](c)
+	require.NoError(t, users.Set(ctx, "user", testUser{ID: 1, Name: "Ada"}, time.Minute))
+
+	raw, err := c.Get(ctx, "user")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"name":"Ada","tags":null,"meta":null,"createdAt":"0001-01-01T00:00:00Z"}`, raw)
+}
+
+func Test_SetEncodesStructs(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCache(t)
+
+	// A struct stored through the untyped API reads back with the typed one
+	require.NoError(t, c.Set(ctx, "user", testUser{ID: 7, Name: "Grace"}, time.Minute))
+
+	actual, err := cache.NewTypedCache[testUser](c).Get(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, 7, actual.ID)
+	assert.Equal(t, "Grace", actual.Name)
+}
@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// memoryLimiter keeps the counters in process memory. It implements the same
+// algorithms as the Redis scripts.
+type memoryLimiter struct {
+	config Config
+
+	mu        sync.Mutex
+	tats      map[string]time.Time
+	windows   map[string][]time.Time
+	lastSweep time.Time
+}
+
+func newMemoryLimiter(config Config) *memoryLimiter {
+	return &memoryLimiter{
+		config:    config,
+		tats:      make(map[string]time.Time),
+		windows:   make(map[string][]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *memoryLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+func (l *memoryLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if n < 1 {
+		return Result{}, fmt.Errorf("%w: %d", ErrInvalidN, n)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	if l.config.Algorithm == SlidingWindow {
+		return l.slidingWindow(key, n, now), nil
+	}
+
+	return l.gcra(key, n, now), nil
+}
+
+func (l *memoryLimiter) gcra(key string, n int, now time.Time) Result {
+	emission := l.config.emissionInterval()
+	tolerance := emission * time.Duration(l.config.Burst)
+	res := Result{Limit: l.config.capacity()}
+
+	tat := l.tats[key]
+	if tat.Before(now) {
+		tat = now
+	}
+
+	newTAT := tat.Add(emission * time.Duration(n))
+	diff := now.Sub(newTAT.Add(-tolerance))
+
+	if diff < 0 {
+		res.RetryAfter = -diff
+		if emission*time.Duration(n) > tolerance {
+			res.RetryAfter = -1
+		}
+		res.Remaining = int(now.Sub(tat.Add(-tolerance)) / emission)
+		res.ResetAfter = tat.Sub(now)
+		return res
+	}
+
+	l.tats[key] = newTAT
+	res.Allowed = true
+	res.Remaining = int(diff / emission)
+	res.ResetAfter = newTAT.Sub(now)
+
+	return res
+}
+
+func (l *memoryLimiter) slidingWindow(key string, n int, now time.Time) Result {
+	window := l.prune(key, now)
+	res := Result{Limit: l.config.capacity()}
+
+	switch {
+	case len(window)+n <= l.config.Limit:
+		for range n {
+			window = append(window, now)
+		}
+		l.windows[key] = window
+		res.Allowed = true
+	case n > l.config.Limit:
+		res.RetryAfter = -1
+	default:
+		// Wait for enough of the oldest requests to leave the window
+		oldest := window[len(window)+n-l.config.Limit-1]
+		res.RetryAfter = oldest.Add(l.config.Period).Sub(now)
+	}
+
+	res.Remaining = l.config.Limit - len(window)
+	if len(window) > 0 {
+		res.ResetAfter = window[len(window)-1].Add(l.config.Period).Sub(now)
+	}
+
+	return res
+}
+
+// prune drops the requests that left the window of key.
+func (l *memoryLimiter) prune(key string, now time.Time) []time.Time {
+	window := l.windows[key]
+
+	start := 0
+	for start < len(window) && !window[start].After(now.Add(-l.config.Period)) {
+		start++
+	}
+
+	window = window[start:]
+	if len(window) == 0 {
+		delete(l.windows, key)
+	}
+
+	return window
+}
+
+// sweep forgets the keys back to their full limit once per period, so
+// one-off keys such as client IPs do not pile up. The caller holds l.mu.
+func (l *memoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.config.Period {
+		return
+	}
+	l.lastSweep = now
+
+	for key, tat := range l.tats {
+		if !tat.After(now) {
+			delete(l.tats, key)
+		}
+	}
+
+	for key := range l.windows {
+		l.prune(key, now)
+	}
+}
@@ -0,0 +1,158 @@
+// Package ratelimit limits how often a key, such as a user or a client IP,
+// may perform an action. Limits are shared by every replica when they are
+// kept in Redis, or local to the process in memory.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/cache"
+)
+
+var (
+	ErrInvalidLimit     = errors.New("limit and period must be positive")
+	ErrInvalidAlgorithm = errors.New("unknown rate limit algorithm")
+	ErrInvalidN         = errors.New("number of requests must be at least 1")
+)
+
+const DefaultPrefix = "ratelimit:"
+
+// Algorithm selects how requests are counted.
+type Algorithm string
+
+const (
+	// GCRA spaces requests evenly over the period and lets Burst of them
+	// through at once. It stores a single timestamp per key.
+	GCRA Algorithm = "gcra"
+
+	// SlidingWindow allows Limit requests in any window of Period. It keeps
+	// the time of every request, so it is exact but uses memory per request.
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// Config holds the settings of a Limiter.
+type Config struct {
+	// Limit is the number of requests allowed per Period.
+	Limit  int
+	Period time.Duration
+
+	// Burst is the number of requests GCRA lets through at once, it defaults
+	// to Limit. SlidingWindow ignores it.
+	Burst int
+
+	// Algorithm defaults to GCRA.
+	Algorithm Algorithm
+
+	// Prefix is prepended to the keys stored in Redis, it defaults to
+	// ratelimit:. Limiters with different limits on the same Redis need
+	// different prefixes.
+	Prefix string
+}
+
+// Result is the outcome of a rate limit check.
+type Result struct {
+	// Allowed reports whether the request may proceed.
+	Allowed bool
+
+	// Limit is the number of requests allowed at once.
+	Limit int
+
+	// Remaining is the number of requests still allowed right now.
+	Remaining int
+
+	// RetryAfter is how long to wait before the request would be allowed.
+	// It is zero when the request is allowed, and -1 when it asks for more
+	// than Limit and will never be allowed.
+	RetryAfter time.Duration
+
+	// ResetAfter is how long until the key is back to its full limit.
+	ResetAfter time.Duration
+}
+
+// Limiter checks requests against a rate limit.
+type Limiter interface {
+	// Allow checks a single request for key.
+	Allow(ctx context.Context, key string) (Result, error)
+
+	// AllowN checks n requests at once, they are all allowed or all denied.
+	// It fails with ErrInvalidN when n is less than 1.
+	AllowN(ctx context.Context, key string, n int) (Result, error)
+}
+
+// New returns a Limiter that shares its counters through the Redis connection
+// of c, so the limit holds across replicas. When c keeps its data in memory
+// the counters are local to the process.
+//
+// Example:
+//
+//	limiter, err := ratelimit.New(c, ratelimit.Config{
+//	    Limit:  100,
+//	    Period: time.Minute,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//
+//	res, err := limiter.Allow(ctx, "user:42")
+func New(c cache.Cache, config Config) (Limiter, error) {
+	config, err := withDefaults(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if client, ok := cache.RedisClient(c); ok {
+		return newRedisLimiter(client, config), nil
+	}
+
+	return newMemoryLimiter(config), nil
+}
+
+// NewMemory returns a Limiter that keeps its counters in process memory.
+func NewMemory(config Config) (Limiter, error) {
+	config, err := withDefaults(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMemoryLimiter(config), nil
+}
+
+func withDefaults(config Config) (Config, error) {
+	if config.Limit <= 0 || config.Period <= 0 {
+		return config, ErrInvalidLimit
+	}
+
+	if config.Burst <= 0 {
+		config.Burst = config.Limit
+	}
+
+	switch config.Algorithm {
+	case "":
+		config.Algorithm = GCRA
+	case GCRA, SlidingWindow:
+	default:
+		return config, ErrInvalidAlgorithm
+	}
+
+	if config.Prefix == "" {
+		config.Prefix = DefaultPrefix
+	}
+
+	return config, nil
+}
+
+// emissionInterval is the time GCRA spaces requests by.
+func (c Config) emissionInterval() time.Duration {
+	return max(c.Period/time.Duration(c.Limit), time.Microsecond)
+}
+
+// capacity returns the number of requests allowed at once.
+func (c Config) capacity() int {
+	if c.Algorithm == GCRA {
+		return c.Burst
+	}
+
+	return c.Limit
+}
@@ -0,0 +1,243 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/cache"
+	"github.com/DucTran999/shared-pkg/ratelimit"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedisCache(t *testing.T, mr *miniredis.Miniredis) cache.Cache {
+	c, err := cache.NewCache(cache.Config{DSN: "redis://" + mr.Addr()})
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+
+	return c
+}
+
+// limiters returns a factory per backend.
+func limiters() map[string]func(t *testing.T, config ratelimit.Config) ratelimit.Limiter {
+	return map[string]func(t *testing.T, config ratelimit.Config) ratelimit.Limiter{
+		"memory": func(t *testing.T, config ratelimit.Config) ratelimit.Limiter {
+			limiter, err := ratelimit.NewMemory(config)
+			require.NoError(t, err)
+			return limiter
+		},
+		"redis": func(t *testing.T, config ratelimit.Config) ratelimit.Limiter {
+			limiter, err := ratelimit.New(newRedisCache(t, miniredis.RunT(t)), config)
+			require.NoError(t, err)
+			return limiter
+		},
+	}
+}
+
+func Test_GCRA(t *testing.T) {
+	for name, newLimiter := range limiters() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			limiter := newLimiter(t, ratelimit.Config{Limit: 5, Period: time.Minute})
+
+			for i := range 5 {
+				res, err := limiter.Allow(ctx, "user:42")
+				require.NoError(t, err)
+				assert.True(t, res.Allowed, "request %d", i)
+				assert.Equal(t, 5, res.Limit)
+				assert.Equal(t, 4-i, res.Remaining)
+				assert.Zero(t, res.RetryAfter)
+			}
+
+			res, err := limiter.Allow(ctx, "user:42")
+			require.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.Equal(t, 0, res.Remaining)
+			assert.InDelta(t, 12*time.Second, res.RetryAfter, float64(100*time.Millisecond), "one request every 12s")
+			assert.InDelta(t, time.Minute, res.ResetAfter, float64(100*time.Millisecond))
+
+			res, err = limiter.Allow(ctx, "user:7")
+			require.NoError(t, err)
+			assert.True(t, res.Allowed, "keys are limited independently")
+		})
+	}
+}
+
+func Test_GCRARefills(t *testing.T) {
+	for name, newLimiter := range limiters() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			limiter := newLimiter(t, ratelimit.Config{Limit: 10, Period: time.Second, Burst: 1})
+
+			res, err := limiter.Allow(ctx, "ip:10.0.0.1")
+			require.NoError(t, err)
+			require.True(t, res.Allowed)
+			assert.Equal(t, 1, res.Limit)
+
+			res, err = limiter.Allow(ctx, "ip:10.0.0.1")
+			require.NoError(t, err)
+			require.False(t, res.Allowed)
+			assert.Positive(t, res.RetryAfter)
+			assert.LessOrEqual(t, res.RetryAfter, 100*time.Millisecond)
+
+			time.Sleep(res.RetryAfter)
+
+			res, err = limiter.Allow(ctx, "ip:10.0.0.1")
+			require.NoError(t, err)
+			assert.True(t, res.Allowed)
+		})
+	}
+}
+
+func Test_SlidingWindow(t *testing.T) {
+	for name, newLimiter := range limiters() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			limiter := newLimiter(t, ratelimit.Config{
+				Limit:     3,
+				Period:    200 * time.Millisecond,
+				Algorithm: ratelimit.SlidingWindow,
+			})
+
+			for i := range 3 {
+				res, err := limiter.Allow(ctx, "user:42")
+				require.NoError(t, err)
+				assert.True(t, res.Allowed, "request %d", i)
+				assert.Equal(t, 3, res.Limit)
+				assert.Equal(t, 2-i, res.Remaining)
+			}
+
+			res, err := limiter.Allow(ctx, "user:42")
+			require.NoError(t, err)
+			assert.False(t, res.Allowed)
+			assert.Equal(t, 0, res.Remaining)
+			assert.Positive(t, res.RetryAfter)
+			assert.LessOrEqual(t, res.RetryAfter, 200*time.Millisecond)
+
+			time.Sleep(res.RetryAfter + 10*time.Millisecond)
+
+			res, err = limiter.Allow(ctx, "user:42")
+			require.NoError(t, err)
+			assert.True(t, res.Allowed, "the oldest request left the window")
+		})
+	}
+}
+
+func Test_AllowN(t *testing.T) {
+	for _, algorithm := range []ratelimit.Algorithm{ratelimit.GCRA, ratelimit.SlidingWindow} {
+		for name, newLimiter := range limiters() {
+			t.Run(string(algorithm)+"/"+name, func(t *testing.T) {
+				ctx := context.Background()
+				limiter := newLimiter(t, ratelimit.Config{Limit: 10, Period: time.Minute, Algorithm: algorithm})
+
+				res, err := limiter.AllowN(ctx, "batch", 7)
+				require.NoError(t, err)
+				assert.True(t, res.Allowed)
+				assert.Equal(t, 3, res.Remaining)
+
+				res, err = limiter.AllowN(ctx, "batch", 4)
+				require.NoError(t, err)
+				assert.False(t, res.Allowed, "all or nothing")
+				assert.Equal(t, 3, res.Remaining)
+				assert.Positive(t, res.RetryAfter)
+
+				res, err = limiter.AllowN(ctx, "batch", 11)
+				require.NoError(t, err)
+				assert.False(t, res.Allowed)
+				assert.Equal(t, time.Duration(-1), res.RetryAfter, "never allowed")
+			})
+		}
+	}
+}
+
+func Test_AllowNInvalid(t *testing.T) {
+	testcases := []struct {
+		name string
+		n    int
+	}{
+		{name: "zero", n: 0},
+		{name: "negative", n: -5},
+	}
+
+	for _, algorithm := range []ratelimit.Algorithm{ratelimit.GCRA, ratelimit.SlidingWindow} {
+		for name, newLimiter := range limiters() {
+			t.Run(string(algorithm)+"/"+name, func(t *testing.T) {
+				ctx := context.Background()
+				limiter := newLimiter(t, ratelimit.Config{Limit: 2, Period: time.Minute, Algorithm: algorithm})
+
+				for _, tc := range testcases {
+					_, err := limiter.AllowN(ctx, "batch", tc.n)
+					require.ErrorIs(t, err, ratelimit.ErrInvalidN, tc.name)
+				}
+
+				// The rejected calls must not have granted extra quota
+				res, err := limiter.AllowN(ctx, "batch", 3)
+				require.NoError(t, err)
+				assert.False(t, res.Allowed)
+			})
+		}
+	}
+}
+
+func Test_RedisLimitIsShared(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	config := ratelimit.Config{Limit: 2, Period: time.Minute}
+
+	first, err := ratelimit.New(newRedisCache(t, mr), config)
+	require.NoError(t, err)
+	second, err := ratelimit.New(newRedisCache(t, mr), config)
+	require.NoError(t, err)
+
+	for _, limiter := range []ratelimit.Limiter{first, second} {
+		res, err := limiter.Allow(ctx, "user:42")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+	}
+
+	res, err := first.Allow(ctx, "user:42")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+
+	assert.True(t, mr.Exists("ratelimit:user:42"))
+}
+
+func Test_NewWithMemoryCache(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := cache.NewCache(cache.Config{IsCacheOnMemory: true})
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+
+	limiter, err := ratelimit.New(c, ratelimit.Config{Limit: 1, Period: time.Minute})
+	require.NoError(t, err)
+
+	res, err := limiter.Allow(ctx, "user:42")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	res, err = limiter.Allow(ctx, "user:42")
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+}
+
+func Test_InvalidConfig(t *testing.T) {
+	testcases := []struct {
+		name     string
+		config   ratelimit.Config
+		expected error
+	}{
+		{name: "zero limit", config: ratelimit.Config{Period: time.Second}, expected: ratelimit.ErrInvalidLimit},
+		{name: "zero period", config: ratelimit.Config{Limit: 1}, expected: ratelimit.ErrInvalidLimit},
+		{name: "unknown algorithm", config: ratelimit.Config{Limit: 1, Period: time.Second, Algorithm: "token_bucket"}, expected: ratelimit.ErrInvalidAlgorithm},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ratelimit.NewMemory(tc.config)
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The scripts read the clock of Redis, so replicas with skewed clocks still
+// agree, and work in microseconds.
+var (
+	// gcraScript stores the theoretical arrival time (TAT) of the next request.
+	// ARGV: burst, emission interval, n.
+	gcraScript = redis.NewScript(`
+redis.replicate_commands()
+
+local burst = tonumber(ARGV[1])
+local emission = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local tolerance = emission * burst
+local tat = math.max(tonumber(redis.call("GET", KEYS[1])) or now, now)
+local new_tat = tat + emission * n
+local diff = now - (new_tat - tolerance)
+
+if diff < 0 then
+	local retry_after = -diff
+	if emission * n > tolerance then
+		retry_after = -1
+	end
+	local remaining = math.floor((now - (tat - tolerance)) / emission)
+	return {0, remaining, retry_after, tat - now}
+end
+
+local reset_after = new_tat - now
+if reset_after > 0 then
+	redis.call("SET", KEYS[1], string.format("%d", new_tat), "PX", math.ceil(reset_after / 1000))
+end
+
+return {1, math.floor(diff / emission), 0, reset_after}
+`)
+
+	// slidingWindowScript stores the time of every request in a sorted set.
+	// ARGV: limit, period, n, unique member prefix.
+	slidingWindowScript = redis.NewScript(`
+redis.replicate_commands()
+
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - period)
+local count = redis.call("ZCARD", KEYS[1])
+
+local allowed = 0
+local retry_after = 0
+if count + n <= limit then
+	for i = 1, n do
+		redis.call("ZADD", KEYS[1], now, ARGV[4] .. ":" .. i)
+	end
+	redis.call("PEXPIRE", KEYS[1], math.ceil(period / 1000))
+	count = count + n
+	allowed = 1
+elseif n > limit then
+	retry_after = -1
+else
+	-- Wait for enough of the oldest requests to leave the window
+	local oldest = redis.call("ZRANGE", KEYS[1], count + n - limit - 1, count + n - limit - 1, "WITHSCORES")
+	retry_after = tonumber(oldest[2]) + period - now
+end
+
+local reset_after = 0
+local newest = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")
+if #newest > 0 then
+	reset_after = tonumber(newest[2]) + period - now
+end
+
+return {allowed, limit - count, retry_after, reset_after}
+`)
+)
+
+// redisLimiter keeps the counters in Redis.
+type redisLimiter struct {
+	client redis.UniversalClient
+	config Config
+}
+
+func newRedisLimiter(client redis.UniversalClient, config Config) *redisLimiter {
+	return &redisLimiter{client: client, config: config}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+func (l *redisLimiter) AllowN(ctx context.Context, key string, n int) (Result, error) {
+	if n < 1 {
+		return Result{}, fmt.Errorf("%w: %d", ErrInvalidN, n)
+	}
+
+	keys := []string{l.config.Prefix + key}
+
+	var cmd *redis.Cmd
+	switch l.config.Algorithm {
+	case SlidingWindow:
+		member, err := newMember()
+		if err != nil {
+			return Result{}, err
+		}
+
+		cmd = slidingWindowScript.Run(ctx, l.client, keys,
+			l.config.Limit, l.config.Period.Microseconds(), n, member)
+	default:
+		cmd = gcraScript.Run(ctx, l.client, keys,
+			l.config.Burst, l.config.emissionInterval().Microseconds(), n)
+	}
+
+	values, err := cmd.Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to check rate limit of %s: %w", key, err)
+	}
+
+	if len(values) != 4 {
+		return Result{}, fmt.Errorf("failed to check rate limit of %s: unexpected reply %v", key, values)
+	}
+
+	res := Result{
+		Allowed:    values[0] == 1,
+		Limit:      l.config.capacity(),
+		Remaining:  max(int(values[1]), 0),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+		ResetAfter: time.Duration(values[3]) * time.Microsecond,
+	}
+
+	if values[2] < 0 {
+		res.RetryAfter = -1
+	}
+
+	return res, nil
+}
+
+// newMember returns a unique sorted set member, so requests made in the
+// same microsecond are all counted.
+func newMember() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate rate limit member: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
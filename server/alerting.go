@@ -0,0 +1,496 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrNilAlerter          = errors.New("alerter must not be nil")
+	ErrInvalidAlertingRate = errors.New("error rate threshold must be between 0 and 1")
+)
+
+const (
+	DefaultPanicAlertName     = "HTTPHandlerPanic"
+	DefaultErrorRateAlertName = "HTTPHighErrorRate"
+
+	DefaultErrorRateWindow    time.Duration = time.Minute
+	DefaultErrorRateThreshold float64       = 0.05
+	DefaultErrorRateMinCount  int           = 20
+	DefaultEvaluationInterval time.Duration = 10 * time.Second
+	DefaultPanicAlertDuration time.Duration = 5 * time.Minute
+
+	// windowBuckets is the number of slots the sliding window is split into.
+	windowBuckets = 10
+
+	// maxAnnotationLength keeps the panic annotations well below the
+	// Alertmanager limits.
+	maxAnnotationLength = 4096
+)
+
+// knownMethods are the methods kept as label values for unmatched routes,
+// the others are counted as "other".
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// Alerter is the part of the alertmanager client used by the alerting middleware.
+// Clients returned by alertmanager.NewAlertManager and NewSinkAlertManager implement it.
+type Alerter interface {
+	alertmanager.ContextAlertManager
+	Alert(opts ...alertmanager.Options) (*alertmanager.Alert, error)
+}
+
+// AlertingConfig holds the configuration settings for the alerting middleware.
+type AlertingConfig struct {
+	// Labels are added to every alert, for example the service name.
+	Labels alertmanager.Labels
+
+	// PanicAlertName is the alertname of the alert fired when a handler panics.
+	PanicAlertName string
+
+	// PanicAlertDuration is how long a panic alert stays active.
+	// A new panic on the same route pushes it forward.
+	PanicAlertDuration time.Duration
+
+	// ErrorRateAlertName is the alertname of the alert fired when the share
+	// of 5xx responses on a route crosses ErrorRateThreshold.
+	ErrorRateAlertName string
+
+	// ErrorRateWindow is the sliding window the 5xx rate is computed over.
+	ErrorRateWindow time.Duration
+
+	// ErrorRateThreshold is the share of 5xx responses, between 0 and 1,
+	// at which the error rate alert fires.
+	ErrorRateThreshold float64
+
+	// ErrorRateMinCount is the number of requests a route needs within the
+	// window before its error rate is considered, so a single failed request
+	// on a quiet route does not page anyone.
+	ErrorRateMinCount int
+
+	// EvaluationInterval is how often the error rates are checked.
+	EvaluationInterval time.Duration
+
+	// DisablePanicAlerts and DisableErrorRateAlerts turn off one kind of alert.
+	DisablePanicAlerts     bool
+	DisableErrorRateAlerts bool
+}
+
+// AlertingMiddleware fires alerts through Alertmanager when Gin handlers panic
+// or when the 5xx rate of a route is too high. Alerts carry the route and
+// method labels, error rate alerts resolve on their own once the rate drops
+// back below the threshold.
+type AlertingMiddleware struct {
+	alerter Alerter
+	config  AlertingConfig
+
+	mu      sync.Mutex
+	windows map[routeKey]*routeWindow
+
+	wg       sync.WaitGroup
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+type routeKey struct {
+	route  string
+	method string
+}
+
+// routeWindow counts the requests and 5xx responses of one route.
+type routeWindow struct {
+	window *slidingWindow
+	alert  *alertmanager.Alert
+}
+
+// NewAlertingMiddleware creates the middleware and starts evaluating error rates
+// in the background. Call Close on shutdown to stop it.
+//
+// Zero values in config fall back to:
+//   - PanicAlertName: HTTPHandlerPanic
+//   - PanicAlertDuration: 5m
+//   - ErrorRateAlertName: HTTPHighErrorRate
+//   - ErrorRateWindow: 1m
+//   - ErrorRateThreshold: 0.05
+//   - ErrorRateMinCount: 20
+//   - EvaluationInterval: 10s
+//
+// Register the handler after gin.Recovery, the panic is re-raised once the
+// alert is on its way:
+//
+//	alerting, err := server.NewAlertingMiddleware(am, server.AlertingConfig{
+//	    Labels: alertmanager.Labels{"service": "checkout"},
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer alerting.Close(ctx)
+//
+//	router := gin.New()
+//	router.Use(gin.Recovery(), alerting.Handler())
+func NewAlertingMiddleware(alerter Alerter, config AlertingConfig) (*AlertingMiddleware, error) {
+	if alerter == nil {
+		return nil, ErrNilAlerter
+	}
+
+	if config.ErrorRateThreshold < 0 || config.ErrorRateThreshold > 1 {
+		return nil, ErrInvalidAlertingRate
+	}
+
+	if config.PanicAlertName == "" {
+		config.PanicAlertName = DefaultPanicAlertName
+	}
+
+	if config.PanicAlertDuration <= 0 {
+		config.PanicAlertDuration = DefaultPanicAlertDuration
+	}
+
+	if config.ErrorRateAlertName == "" {
+		config.ErrorRateAlertName = DefaultErrorRateAlertName
+	}
+
+	if config.ErrorRateWindow <= 0 {
+		config.ErrorRateWindow = DefaultErrorRateWindow
+	}
+
+	if config.ErrorRateThreshold == 0 {
+		config.ErrorRateThreshold = DefaultErrorRateThreshold
+	}
+
+	if config.ErrorRateMinCount <= 0 {
+		config.ErrorRateMinCount = DefaultErrorRateMinCount
+	}
+
+	if config.EvaluationInterval <= 0 {
+		config.EvaluationInterval = DefaultEvaluationInterval
+	}
+
+	m := &AlertingMiddleware{
+		alerter: alerter,
+		config:  config,
+		windows: make(map[routeKey]*routeWindow),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	if config.DisableErrorRateAlerts {
+		close(m.stopped)
+	} else {
+		go m.evaluateLoop()
+	}
+
+	return m, nil
+}
+
+// Handler returns the Gin middleware.
+func (m *AlertingMiddleware) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := routeKey{route: c.FullPath(), method: c.Request.Method}
+		if key.route == "" {
+			// Any method reaches an unmatched route, keep the label values bounded
+			key.route = "unmatched"
+			if !knownMethods[key.method] {
+				key.method = "other"
+			}
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				// The recovery middleware answers with 500 after the re-panic
+				m.record(key, http.StatusInternalServerError)
+
+				if !m.config.DisablePanicAlerts {
+					m.firePanicAlert(c.Request.Context(), key, r, debug.Stack())
+				}
+
+				panic(r)
+			}
+
+			m.record(key, c.Writer.Status())
+		}()
+
+		c.Next()
+	}
+}
+
+// Close stops the error rate evaluation, resolves the error rate alerts still
+// firing and waits for pending panic alerts.
+func (m *AlertingMiddleware) Close(ctx context.Context) error {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+
+	select {
+	case <-m.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	m.mu.Lock()
+	var alerts []*alertmanager.Alert
+	for _, w := range m.windows {
+		if w.alert != nil {
+			alerts = append(alerts, w.alert)
+		}
+	}
+	m.mu.Unlock()
+
+	// Resolving talks to Alertmanager, requests must not wait for it
+	var errs []error
+	for _, alert := range alerts {
+		if !alert.Firing() {
+			continue
+		}
+		if err := alert.Resolve(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, ctx.Err())
+	}
+
+	return errors.Join(errs...)
+}
+
+// record counts a finished request in the window of its route.
+func (m *AlertingMiddleware) record(key routeKey, status int) {
+	if m.config.DisableErrorRateAlerts {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w, ok := m.windows[key]
+	if !ok {
+		w = &routeWindow{window: newSlidingWindow(m.config.ErrorRateWindow, windowBuckets)}
+		m.windows[key] = w
+	}
+
+	w.window.add(time.Now(), status >= http.StatusInternalServerError)
+}
+
+// firePanicAlert sends the panic alert in the background so the request is
+// not held up by Alertmanager.
+func (m *AlertingMiddleware) firePanicAlert(ctx context.Context, key routeKey, recovered any, stack []byte) {
+	opts := []alertmanager.Options{
+		alertmanager.WithLabels(m.labels(m.config.PanicAlertName, key)),
+		alertmanager.WithAnnotations(alertmanager.Annotations{
+			"summary":     fmt.Sprintf("handler panicked on %s %s", key.method, key.route),
+			"description": truncateAnnotation(fmt.Sprint(recovered)),
+			"stack":       truncateAnnotation(string(stack)),
+		}),
+		alertmanager.WithStartsAt(time.Now()),
+		alertmanager.WithDuration(m.config.PanicAlertDuration),
+	}
+
+	// Keep the request values but not its cancellation, the request is over
+	ctx = context.WithoutCancel(ctx)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ctx, cancel := context.WithTimeout(ctx, alertmanager.DefaultClientTimeout)
+		defer cancel()
+
+		if err := m.alerter.SendContext(ctx, opts...); err != nil {
+			log.Error().Err(err).Str("route", key.route).Str("method", key.method).Msg("failed to send panic alert")
+		}
+	}()
+}
+
+// truncateAnnotation cuts s to maxAnnotationLength on a rune boundary and
+// replaces invalid UTF-8, which the alertmanager client rejects.
+func truncateAnnotation(s string) string {
+	s = strings.ToValidUTF8(s, string(utf8.RuneError))
+	if len(s) <= maxAnnotationLength {
+		return s
+	}
+
+	n := maxAnnotationLength
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
+
+func (m *AlertingMiddleware) evaluateLoop() {
+	defer close(m.stopped)
+
+	ticker := time.NewTicker(m.config.EvaluationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.evaluate(time.Now())
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// evaluate fires the error rate alert of every route above the threshold and
+// resolves the ones that dropped back below it.
+func (m *AlertingMiddleware) evaluate(now time.Time) {
+	type transition struct {
+		key  routeKey
+		w    *routeWindow
+		fire bool
+	}
+
+	var transitions []transition
+
+	m.mu.Lock()
+	for key, w := range m.windows {
+		total, failed := w.window.counts(now)
+		firing := w.alert != nil && w.alert.Firing()
+
+		breached := total >= m.config.ErrorRateMinCount &&
+			float64(failed)/float64(total) >= m.config.ErrorRateThreshold
+
+		switch {
+		case breached != firing:
+			transitions = append(transitions, transition{key: key, w: w, fire: breached})
+
+		case total == 0 && !firing:
+			// Forget idle routes so the map does not grow forever
+			delete(m.windows, key)
+		}
+	}
+	m.mu.Unlock()
+
+	// Talk to Alertmanager without holding the lock the requests need
+	ctx, cancel := context.WithTimeout(context.Background(), alertmanager.DefaultClientTimeout)
+	defer cancel()
+
+	for _, t := range transitions {
+		if t.fire {
+			if err := m.fireErrorRateAlert(ctx, t.key, t.w); err != nil {
+				log.Error().Err(err).Str("route", t.key.route).Str("method", t.key.method).Msg("failed to fire error rate alert")
+			}
+			continue
+		}
+
+		if err := t.w.alert.Resolve(ctx); err != nil {
+			log.Error().Err(err).Str("route", t.key.route).Str("method", t.key.method).Msg("failed to resolve error rate alert")
+		}
+	}
+}
+
+func (m *AlertingMiddleware) fireErrorRateAlert(ctx context.Context, key routeKey, w *routeWindow) error {
+	if w.alert == nil {
+		alert, err := m.alerter.Alert(
+			alertmanager.WithLabels(m.labels(m.config.ErrorRateAlertName, key)),
+			alertmanager.WithAnnotations(alertmanager.Annotations{
+				"summary": fmt.Sprintf("5xx rate above %g%% on %s %s over %s",
+					m.config.ErrorRateThreshold*100, key.method, key.route, m.config.ErrorRateWindow),
+			}),
+		)
+		if err != nil {
+			return err
+		}
+		w.alert = alert
+	}
+
+	return w.alert.Fire(ctx)
+}
+
+// labels returns the configured labels with the alert name, route and method.
+func (m *AlertingMiddleware) labels(alertName string, key routeKey) alertmanager.Labels {
+	labels := make(alertmanager.Labels, len(m.config.Labels)+3)
+	for name, value := range m.config.Labels {
+		labels[name] = value
+	}
+
+	labels[alertmanager.AlertNameLabel] = alertName
+	labels["route"] = key.route
+	labels["method"] = key.method
+
+	return labels
+}
+
+// slidingWindow counts events over the last window, split into buckets so
+// old events expire gradually instead of all at once.
+type slidingWindow struct {
+	width   time.Duration
+	buckets []windowBucket
+}
+
+type windowBucket struct {
+	slot   int64
+	total  int
+	failed int
+}
+
+func newSlidingWindow(window time.Duration, buckets int) *slidingWindow {
+	width := window / time.Duration(buckets)
+	if width <= 0 {
+		width = 1
+	}
+
+	return &slidingWindow{
+		width:   width,
+		buckets: make([]windowBucket, buckets),
+	}
+}
+
+func (w *slidingWindow) add(now time.Time, failed bool) {
+	slot := now.UnixNano() / int64(w.width)
+	b := &w.buckets[slot%int64(len(w.buckets))]
+
+	// The bucket still holds an older slot, start it over
+	if b.slot != slot {
+		*b = windowBucket{slot: slot}
+	}
+
+	b.total++
+	if failed {
+		b.failed++
+	}
+}
+
+func (w *slidingWindow) counts(now time.Time) (total, failed int) {
+	current := now.UnixNano() / int64(w.width)
+
+	for _, b := range w.buckets {
+		if b.slot > current-int64(len(w.buckets)) && b.slot <= current {
+			total += b.total
+			failed += b.failed
+		}
+	}
+
+	return total, failed
+}
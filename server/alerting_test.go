@@ -0,0 +1,208 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DucTran999/shared-pkg/alertmanager"
+	"github.com/DucTran999/shared-pkg/alertmanager/alertmanagertest"
+	"github.com/DucTran999/shared-pkg/server"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAlertingRouter(t *testing.T, config server.AlertingConfig) (*gin.Engine, *server.AlertingMiddleware, *alertmanagertest.Server) {
+	gin.SetMode(gin.TestMode)
+
+	fake := alertmanagertest.NewServer(t)
+
+	am, err := alertmanager.NewAlertManager(fake.URL)
+	require.NoError(t, err)
+
+	alerting, err := server.NewAlertingMiddleware(am, config)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(gin.Recovery(), alerting.Handler())
+	router.GET("/orders/:id", func(c *gin.Context) {
+		panic("nil order")
+	})
+	router.GET("/payments", func(c *gin.Context) {
+		c.Status(http.StatusBadGateway)
+	})
+
+	return router, alerting, fake
+}
+
+// isFiring reports whether the fake holds an unresolved alert with these labels.
+func isFiring(fake *alertmanagertest.Server, labels alertmanager.Labels) bool {
+	for _, a := range fake.Alerts() {
+		if assert.ObjectsAreEqual(labels, a.Labels) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func serve(router *gin.Engine, path string) int {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return rec.Code
+}
+
+func Test_AlertingMiddlewarePanic(t *testing.T) {
+	router, alerting, fake := newAlertingRouter(t, server.AlertingConfig{
+		Labels:                 alertmanager.Labels{"service": "checkout"},
+		DisableErrorRateAlerts: true,
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, serve(router, "/orders/42"))
+
+	// Close waits for the panic alert to be delivered
+	require.NoError(t, alerting.Close(context.Background()))
+
+	labels := alertmanager.Labels{
+		"alertname": server.DefaultPanicAlertName,
+		"service":   "checkout",
+		"route":     "/orders/:id",
+		"method":    http.MethodGet,
+	}
+	fake.AssertFiring(t, labels)
+
+	received := fake.Received()
+	require.Len(t, received, 1)
+	assert.Equal(t, "nil order", received[0].Annotations["description"])
+	assert.Contains(t, received[0].Annotations["stack"], "goroutine")
+}
+
+func Test_AlertingMiddlewarePanicValueIsValidUTF8(t *testing.T) {
+	testcases := []struct {
+		name      string
+		recovered string
+	}{
+		// The cut at 4096 bytes falls inside a two byte rune
+		{name: "multi-byte rune at the cut", recovered: "x" + strings.Repeat("é", 3000)},
+		{name: "invalid bytes", recovered: "bad value \xff\xfe"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, alerting, fake := newAlertingRouter(t, server.AlertingConfig{DisableErrorRateAlerts: true})
+			router.GET("/refunds", func(c *gin.Context) {
+				panic(tc.recovered)
+			})
+
+			assert.Equal(t, http.StatusInternalServerError, serve(router, "/refunds"))
+			require.NoError(t, alerting.Close(context.Background()))
+
+			received := fake.Received()
+			require.Len(t, received, 1, "the panic alert must not be dropped")
+
+			description := received[0].Annotations["description"]
+			assert.True(t, utf8.ValidString(description))
+			assert.LessOrEqual(t, len(description), 4096)
+			assert.True(t, utf8.ValidString(received[0].Annotations["stack"]))
+		})
+	}
+}
+
+func Test_AlertingMiddlewareErrorRate(t *testing.T) {
+	router, alerting, fake := newAlertingRouter(t, server.AlertingConfig{
+		ErrorRateWindow:    300 * time.Millisecond,
+		ErrorRateThreshold: 0.5,
+		ErrorRateMinCount:  5,
+		EvaluationInterval: 20 * time.Millisecond,
+	})
+	defer alerting.Close(context.Background())
+
+	labels := alertmanager.Labels{
+		"alertname": server.DefaultErrorRateAlertName,
+		"route":     "/payments",
+		"method":    http.MethodGet,
+	}
+
+	// Too few requests to be considered
+	for range 4 {
+		assert.Equal(t, http.StatusBadGateway, serve(router, "/payments"))
+	}
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, fake.Received())
+
+	assert.Equal(t, http.StatusBadGateway, serve(router, "/payments"))
+	require.Eventually(t, func() bool {
+		return isFiring(fake, labels)
+	}, time.Second, 10*time.Millisecond, "error rate alert must fire")
+
+	// The failures leave the window and the alert resolves
+	require.Eventually(t, func() bool {
+		return !isFiring(fake, labels)
+	}, 2*time.Second, 10*time.Millisecond, "error rate alert must resolve")
+	fake.AssertResolved(t, labels)
+}
+
+func Test_AlertingMiddlewareCloseResolves(t *testing.T) {
+	router, alerting, fake := newAlertingRouter(t, server.AlertingConfig{
+		ErrorRateWindow:    time.Hour,
+		ErrorRateMinCount:  1,
+		EvaluationInterval: 10 * time.Millisecond,
+	})
+
+	labels := alertmanager.Labels{
+		"alertname": server.DefaultErrorRateAlertName,
+		"route":     "/payments",
+		"method":    http.MethodGet,
+	}
+
+	serve(router, "/payments")
+	require.Eventually(t, func() bool {
+		return isFiring(fake, labels)
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, alerting.Close(context.Background()))
+	fake.AssertResolved(t, labels)
+}
+
+func Test_AlertingMiddlewareUnmatchedRoute(t *testing.T) {
+	router, alerting, fake := newAlertingRouter(t, server.AlertingConfig{
+		ErrorRateWindow:    time.Hour,
+		ErrorRateMinCount:  1,
+		EvaluationInterval: 10 * time.Millisecond,
+	})
+	defer alerting.Close(context.Background())
+
+	router.NoRoute(func(c *gin.Context) {
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("PROPFIND", "/missing", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	labels := alertmanager.Labels{
+		"alertname": server.DefaultErrorRateAlertName,
+		"route":     "unmatched",
+		"method":    "other",
+	}
+	require.Eventually(t, func() bool {
+		return isFiring(fake, labels)
+	}, time.Second, 10*time.Millisecond, "unknown methods must share one label value")
+}
+
+func Test_NewAlertingMiddlewareValidation(t *testing.T) {
+	_, err := server.NewAlertingMiddleware(nil, server.AlertingConfig{})
+	require.ErrorIs(t, err, server.ErrNilAlerter)
+
+	am, err := alertmanager.NewAlertManager("http://localhost:9093")
+	require.NoError(t, err)
+
+	_, err = server.NewAlertingMiddleware(am, server.AlertingConfig{ErrorRateThreshold: 1.5})
+	require.ErrorIs(t, err, server.ErrInvalidAlertingRate)
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+var ErrNilLimiter = errors.New("limiter must not be nil")
+
+// RateLimitKeyFunc returns the key a request is counted under, such as the
+// user or the client IP. An empty key lets the request through unchecked.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitConfig holds the configuration settings for the rate limit middleware.
+type RateLimitConfig struct {
+	// KeyFunc defaults to RateLimitByIP.
+	KeyFunc RateLimitKeyFunc
+
+	// LimitedHandler answers the requests over the limit, after the headers
+	// are set. It defaults to a 429 with a JSON error.
+	LimitedHandler gin.HandlerFunc
+
+	// FailClosed answers 503 when the limiter fails, for example when Redis
+	// is down. By default such requests are let through.
+	FailClosed bool
+}
+
+// RateLimitByIP counts requests per client IP.
+func RateLimitByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitByContextKey counts requests per value of a Gin context key, such
+// as the user ID set by an authentication middleware, and per client IP when
+// the key is not set.
+func RateLimitByContextKey(key string) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		if value, ok := c.Get(key); ok && value != nil {
+			return fmt.Sprintf("%s:%v", key, value)
+		}
+
+		return RateLimitByIP(c)
+	}
+}
+
+// NewRateLimitMiddleware returns a Gin middleware that checks every request
+// against limiter. Responses carry the RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset headers, and Retry-After when the request is rejected.
+//
+// Use a limiter per limit, with its own ratelimit.Config.Prefix when the
+// limiters share a Redis:
+//
+//	limiter, err := ratelimit.New(c, ratelimit.Config{
+//	    Limit:  100,
+//	    Period: time.Minute,
+//	    Prefix: "ratelimit:api:",
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//
+//	limit, err := server.NewRateLimitMiddleware(limiter, server.RateLimitConfig{
+//	    KeyFunc: server.RateLimitByContextKey("user_id"),
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//
+//	router.Use(auth, limit)
+func NewRateLimitMiddleware(limiter ratelimit.Limiter, config RateLimitConfig) (gin.HandlerFunc, error) {
+	if limiter == nil {
+		return nil, ErrNilLimiter
+	}
+
+	if config.KeyFunc == nil {
+		config.KeyFunc = RateLimitByIP
+	}
+
+	if config.LimitedHandler == nil {
+		config.LimitedHandler = func(c *gin.Context) {
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+		}
+	}
+
+	return func(c *gin.Context) {
+		key := config.KeyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		res, err := limiter.Allow(c.Request.Context(), key)
+		if err != nil {
+			log.Error().Err(err).Str("key", key).Msg("rate limit check failed")
+
+			if config.FailClosed {
+				c.AbortWithStatus(http.StatusServiceUnavailable)
+				return
+			}
+
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+
+		if !res.Allowed {
+			if res.RetryAfter > 0 {
+				header.Set("Retry-After", strconv.Itoa(max(ceilSeconds(res.RetryAfter), 1)))
+			}
+
+			config.LimitedHandler(c)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}, nil
+}
+
+// ceilSeconds rounds d up to whole seconds, as the headers expect.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
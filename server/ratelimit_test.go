@@ -0,0 +1,145 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DucTran999/shared-pkg/ratelimit"
+	"github.com/DucTran999/shared-pkg/server"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitRouter(t *testing.T, limiter ratelimit.Limiter, config server.RateLimitConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	limit, err := server.NewRateLimitMiddleware(limiter, config)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			c.Set("user_id", user)
+		}
+	}, limit)
+	router.GET("/orders", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	return router
+}
+
+func get(router *gin.Engine, ip, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.RemoteAddr = ip + ":51000"
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func Test_RateLimitMiddleware(t *testing.T) {
+	limiter, err := ratelimit.NewMemory(ratelimit.Config{Limit: 2, Period: time.Minute})
+	require.NoError(t, err)
+
+	router := newRateLimitRouter(t, limiter, server.RateLimitConfig{})
+
+	rec := get(router, "10.0.0.1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", rec.Header().Get("RateLimit-Reset"))
+	assert.Empty(t, rec.Header().Get("Retry-After"))
+
+	rec = get(router, "10.0.0.1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+
+	rec = get(router, "10.0.0.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"rate limit exceeded"}`, rec.Body.String())
+
+	rec = get(router, "10.0.0.2", "")
+	assert.Equal(t, http.StatusOK, rec.Code, "other clients keep their own limit")
+}
+
+func Test_RateLimitMiddlewareByUser(t *testing.T) {
+	limiter, err := ratelimit.NewMemory(ratelimit.Config{Limit: 1, Period: time.Minute})
+	require.NoError(t, err)
+
+	router := newRateLimitRouter(t, limiter, server.RateLimitConfig{
+		KeyFunc: server.RateLimitByContextKey("user_id"),
+	})
+
+	assert.Equal(t, http.StatusOK, get(router, "10.0.0.1", "alice").Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(router, "10.0.0.2", "alice").Code, "the user is limited from any IP")
+	assert.Equal(t, http.StatusOK, get(router, "10.0.0.1", "bob").Code)
+
+	// Anonymous requests fall back to the client IP
+	assert.Equal(t, http.StatusOK, get(router, "10.0.0.1", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(router, "10.0.0.1", "").Code)
+}
+
+func Test_RateLimitMiddlewareCustomResponse(t *testing.T) {
+	limiter, err := ratelimit.NewMemory(ratelimit.Config{Limit: 1, Period: time.Minute})
+	require.NoError(t, err)
+
+	router := newRateLimitRouter(t, limiter, server.RateLimitConfig{
+		LimitedHandler: func(c *gin.Context) {
+			c.String(http.StatusTooManyRequests, "slow down")
+		},
+	})
+
+	get(router, "10.0.0.1", "")
+	rec := get(router, "10.0.0.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "slow down", rec.Body.String())
+}
+
+// failingLimiter reports an error for every check, like an unreachable Redis.
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func (failingLimiter) AllowN(ctx context.Context, key string, n int) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func Test_RateLimitMiddlewareLimiterFailure(t *testing.T) {
+	testcases := []struct {
+		name     string
+		config   server.RateLimitConfig
+		expected int
+	}{
+		{name: "fail open", config: server.RateLimitConfig{}, expected: http.StatusOK},
+		{name: "fail closed", config: server.RateLimitConfig{FailClosed: true}, expected: http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := newRateLimitRouter(t, failingLimiter{}, tc.config)
+
+			rec := get(router, "10.0.0.1", "")
+			assert.Equal(t, tc.expected, rec.Code)
+			assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+		})
+	}
+}
+
+func Test_NewRateLimitMiddlewareNilLimiter(t *testing.T) {
+	_, err := server.NewRateLimitMiddleware(nil, server.RateLimitConfig{})
+	require.ErrorIs(t, err, server.ErrNilLimiter)
+}